@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/stetide/vector/vector"
+)
+
+// runCheck parses scripts in paths without running them and prints every syntax error
+func runCheck(paths []string) bool {
+	ok := true
+	for _, path := range paths {
+		src, err := ioutil.ReadFile(path)
+		if err != nil {
+			fmt.Println(err)
+			ok = false
+			continue
+		}
+		_, err = vector.NewSession().ParseScript(string(src))
+		if err == nil {
+			continue
+		}
+		ok = false
+		list, isList := err.(vector.ErrorList)
+		if !isList {
+			list = vector.ErrorList{err.(vector.Error)}
+		}
+		for _, e := range list {
+			line, col := position(string(src), e.Span.Start)
+			fmt.Printf("%s:%d:%d: %s\n", path, line, col, e)
+		}
+	}
+	return ok
+}
+
+// position returns line and column of offset in src, both count from 1
+func position(src string, offset int) (line, col int) {
+	before := src[:offset]
+	return strings.Count(before, "\n") + 1, offset - strings.LastIndex(before, "\n")
+}
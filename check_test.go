@@ -0,0 +1,34 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stetide/vector/vector"
+)
+
+func TestRunCheck(t *testing.T) {
+	tests := []struct {
+		src  string
+		pass bool
+	}{
+		{"a = 1\n# comment\n\nb = a + 2\n", true},
+		{"a = 1\nb = a +\n", false},
+		{"$ a = 1\n>> \n$ a + 1\n>> 2\n", true},
+		{"$ 1 + 1 >> 2\n$ [1 2] >> vec(1 2)\n", true},
+		{"$ 1 + * 2\n>> E003: Expected expression\n", false},
+	}
+	for _, tt := range tests {
+		if got := runCheck([]string{transcript(t, tt.src)}); got != tt.pass {
+			t.Errorf("runCheck(%q) = %v, want %v", tt.src, got, tt.pass)
+		}
+	}
+}
+
+func TestErrText(t *testing.T) {
+	_, err := vector.Parse("(1 + * 2")
+	want := "col 6: E003: Expected expression\nend: E004: Expected )"
+	if got := errText(err); !strings.HasPrefix(got, want) {
+		t.Errorf("errText = %q, want %q", got, want)
+	}
+}
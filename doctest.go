@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/stetide/vector/vector"
+)
+
+// example is input of transcript with output expected by it
+type example struct {
+	line  int
+	input string
+	want  []string
+	// output tells if >> line was given
+	output bool
+}
+
+// parseTranscript reads "$ input" lines, "... more" continuation lines
+// and ">> output" lines which may span following lines. Output may also
+// follow input on its line like $ 1 + 1 >> 2
+func parseTranscript(path string) ([]example, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var examples []example
+	var cur *example
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimRight(sc.Text(), " \t\r")
+		switch {
+		case strings.HasPrefix(line, "$ "):
+			ex := example{line: n, input: strings.TrimSpace(line[2:])}
+			// inline form $ input >> output
+			if i := strings.Index(ex.input, " >>"); i >= 0 {
+				ex.want = []string{strings.TrimSpace(ex.input[i+3:])}
+				ex.input, ex.output = strings.TrimSpace(ex.input[:i]), true
+			}
+			examples = append(examples, ex)
+			cur = &examples[len(examples)-1]
+		case cur == nil:
+		case strings.HasPrefix(line, "... ") && !cur.output:
+			cur.input += " " + strings.TrimSpace(line[4:])
+		case strings.HasPrefix(line, ">>"):
+			cur.output = true
+			cur.want = append(cur.want, strings.TrimSpace(line[2:]))
+		case line == "":
+			cur = nil
+		case cur.output:
+			cur.want = append(cur.want, line)
+		}
+	}
+	return examples, sc.Err()
+}
+
+// result returns what REPL pushes for txt
+func result(txt string, render func(vector.Node) string) string {
+	ast, err := vector.Parse(txt)
+	var res vector.Node
+	if err == nil {
+		res, err = vector.Execute(ast)
+	}
+	if err != nil {
+		return errText(err)
+	}
+	if cmd, ok := res.(vector.Command); ok {
+		return cmd.Text
+	} else if res == nil {
+		return ""
+	} else if render != nil {
+		return render(res)
+	}
+	return vector.Show(res)
+}
+
+// runTests checks transcripts in paths, each file starts with empty session
+func runTests(paths []string, render func(vector.Node) string) bool {
+	var passed, failed int
+	for _, path := range paths {
+		examples, err := parseTranscript(path)
+		if err != nil {
+			fmt.Println(err)
+			failed++
+			continue
+		}
+		for _, cmd := range []string{"reset", "format reset", "eps reset", "lang reset"} {
+			vector.Run(cmd)
+		}
+
+		for _, ex := range examples {
+			got := result(ex.input, render)
+			lines := strings.Split(got, "\n")
+			for i, l := range lines {
+				lines[i] = strings.TrimRight(l, " \t")
+			}
+			got = strings.Join(lines, "\n")
+			want := strings.Join(ex.want, "\n")
+			if got == want {
+				passed++
+				continue
+			}
+			failed++
+			fmt.Printf("%s:%d: $ %s\n", path, ex.line, ex.input)
+			if ex.output {
+				fmt.Printf("  expected: %s\n", strings.ReplaceAll(want, "\n", "\n            "))
+			} else {
+				fmt.Println("  expected: no output")
+			}
+			if got == "" {
+				got = "no output"
+			}
+			fmt.Printf("  got:      %s\n", strings.ReplaceAll(got, "\n", "\n            "))
+		}
+	}
+	fmt.Printf("%d passed, %d failed\n", passed, failed)
+	return failed == 0
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// transcript writes src to file in dir of t
+func transcript(t *testing.T, src string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.vec")
+	if err := ioutil.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseTranscript(t *testing.T) {
+	tests := []struct {
+		src  string
+		want []example
+	}{
+		{"$ 1 + 1\n>> 2\n", []example{{line: 1, input: "1 + 1", want: []string{"2"}, output: true}}},
+		{"$ 1 + 1 >> 2\n$ a = 1 >>\n", []example{
+			{line: 1, input: "1 + 1", want: []string{"2"}, output: true},
+			{line: 2, input: "a = 1", want: []string{""}, output: true},
+		}},
+		{"# comment\n$ [1\n... 2]\n>> vec(1\n  2)\n\ntext\n", []example{{line: 2, input: "[1 2]", want: []string{"vec(1", "  2)"}, output: true}}},
+		{"$ a = 2\n$ a\n>> 2", []example{{line: 1, input: "a = 2"}, {line: 2, input: "a", want: []string{"2"}, output: true}}},
+		{"no input\n>> 1\n", nil},
+	}
+	for _, tt := range tests {
+		got, err := parseTranscript(transcript(t, tt.src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseTranscript(%q) = %+v, want %+v", tt.src, got, tt.want)
+		}
+	}
+	if _, err := parseTranscript(filepath.Join(t.TempDir(), "missing.vec")); !os.IsNotExist(err) {
+		t.Errorf("parseTranscript of missing file = %v, want not exist", err)
+	}
+}
+
+func TestRunTests(t *testing.T) {
+	tests := []struct {
+		src  string
+		pass bool
+	}{
+		{"$ 1 + 1 >> 2\n$ a = 3\n$ a * 2\n>> 6\n$ assert a == 3\n", true},
+		{"$ format digits 3\n$ 1 / 3\n>> 0.333\n", true},
+		{"$ 1 / 0\n>> E101: Division by zero\n", true},
+		{"$ 1 + 1 >> 3\n", false},
+		{"$ a = 1\n>> 1\n", false},
+		{"$ assert 1 == 2\n", false},
+		{"$ assert 1\n", false},
+		{"$ 1 +\n>> 1\n", false},
+		// each file starts with empty session and default settings
+		{"$ a\n>> E301: a is not defined\n$ 1 / 3\n>> 0.333333333333333\n", true},
+	}
+	for _, tt := range tests {
+		if got := runTests([]string{transcript(t, tt.src)}, nil); got != tt.pass {
+			t.Errorf("runTests(%q) = %v, want %v", tt.src, got, tt.pass)
+		}
+	}
+	if runTests([]string{filepath.Join(t.TempDir(), "missing.vec")}, nil) {
+		t.Error("runTests of missing file passed")
+	}
+}
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/stetide/vector/vector"
+)
+
+// editor reads lines with cursor movement, history, search and completion
+type editor struct {
+	in      *bufio.Reader
+	raw     bool
+	history []string
+}
+
+func newEditor() *editor {
+	e := &editor{in: bufio.NewReader(os.Stdin)}
+	if fi, err := os.Stdin.Stat(); err == nil && fi.Mode()&os.ModeCharDevice != 0 && runtime.GOOS != "windows" {
+		_, err := stty("-g")
+		e.raw = err == nil
+	}
+	return e
+}
+
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+// clearScreen clears terminal
+func clearScreen() {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "windows":
+		cmd = exec.Command("cmd", "/c", "cls")
+	case "linux", "darwin":
+		cmd = exec.Command("clear")
+	default:
+		return
+	}
+	cmd.Stdout = os.Stdout
+	cmd.Run()
+}
+
+// readLine reads line after prompt, io.EOF ends input
+func (e *editor) readLine(prompt string) (string, error) {
+	if !e.raw {
+		fmt.Print(prompt)
+		txt, err := e.in.ReadString('\n')
+		if err != nil && txt == "" {
+			return "", err
+		}
+		return txt, nil
+	}
+
+	saved, err := stty("-g")
+	if err != nil {
+		e.raw = false
+		return e.readLine(prompt)
+	}
+	stty("raw", "-echo")
+	line, err := e.edit(prompt)
+	stty(saved)
+	fmt.Println()
+
+	if err == nil && strings.TrimSpace(line) != "" {
+		if n := len(e.history); n == 0 || e.history[n-1] != line {
+			e.history = append(e.history, line)
+		}
+	}
+	return line, err
+}
+
+func (e *editor) redraw(prompt string, buf []rune, pos int) {
+	fmt.Print("\r" + prompt + vector.Highlight(string(buf)) + "\x1b[K")
+	if back := len(buf) - pos; back > 0 {
+		fmt.Printf("\x1b[%dD", back)
+	}
+}
+
+func (e *editor) edit(prompt string) (string, error) {
+	var buf []rune
+	var pos int
+	hist, current := len(e.history), ""
+
+	insert := func(rs ...rune) {
+		buf = append(buf[:pos], append(rs, buf[pos:]...)...)
+		pos += len(rs)
+	}
+	browse := func(i int) {
+		if hist == len(e.history) {
+			current = string(buf)
+		}
+		hist = i
+		if hist == len(e.history) {
+			buf = []rune(current)
+		} else {
+			buf = []rune(e.history[hist])
+		}
+		pos = len(buf)
+	}
+
+	e.redraw(prompt, buf, pos)
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+		switch r {
+		case '\r', '\n':
+			return string(buf), nil
+		case 3: // ctrl-c drops line
+			return "", nil
+		case 4: // ctrl-d ends input on empty line
+			if len(buf) == 0 {
+				return "", io.EOF
+			}
+			if pos < len(buf) {
+				buf = append(buf[:pos], buf[pos+1:]...)
+			}
+		case 1:
+			pos = 0
+		case 5:
+			pos = len(buf)
+		case 2:
+			if pos > 0 {
+				pos--
+			}
+		case 6:
+			if pos < len(buf) {
+				pos++
+			}
+		case 11:
+			buf = buf[:pos]
+		case 21:
+			buf, pos = buf[pos:], 0
+		case 23:
+			start := wordStart(buf, pos)
+			if start == pos && pos > 0 {
+				start--
+			}
+			buf, pos = append(buf[:start], buf[pos:]...), start
+		case 127, 8:
+			if pos > 0 {
+				buf = append(buf[:pos-1], buf[pos:]...)
+				pos--
+			}
+		case '\t':
+			buf, pos = e.complete(buf, pos)
+		case 18:
+			line, done := e.search(string(buf))
+			if done {
+				return line, nil
+			}
+			buf = []rune(line)
+			pos = len(buf)
+		case 27:
+			seq := e.escape()
+			switch seq {
+			case "A":
+				if hist > 0 {
+					browse(hist - 1)
+				}
+			case "B":
+				if hist < len(e.history) {
+					browse(hist + 1)
+				}
+			case "C":
+				if pos < len(buf) {
+					pos++
+				}
+			case "D":
+				if pos > 0 {
+					pos--
+				}
+			case "H", "1~", "7~":
+				pos = 0
+			case "F", "4~", "8~":
+				pos = len(buf)
+			case "3~":
+				if pos < len(buf) {
+					buf = append(buf[:pos], buf[pos+1:]...)
+				}
+			}
+		default:
+			if r >= ' ' {
+				insert(r)
+			}
+		}
+		e.redraw(prompt, buf, pos)
+	}
+}
+
+// escape reads rest of escape sequence like [A or [3~
+func (e *editor) escape() string {
+	r, _, err := e.in.ReadRune()
+	if err != nil || r != '[' && r != 'O' {
+		return ""
+	}
+	var seq string
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return seq
+		}
+		seq += string(r)
+		if r >= 'A' && r <= 'Z' || r == '~' {
+			return seq
+		}
+	}
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9'
+}
+
+func wordStart(buf []rune, pos int) int {
+	for pos > 0 && isWordRune(buf[pos-1]) {
+		pos--
+	}
+	return pos
+}
+
+// complete completes word before cursor or lists candidates
+func (e *editor) complete(buf []rune, pos int) ([]rune, int) {
+	start := wordStart(buf, pos)
+	prefix := string(buf[start:pos])
+	if prefix == "" {
+		return buf, pos
+	}
+	cands := vector.Complete(prefix)
+	if len(cands) == 0 {
+		fmt.Print("\a")
+		return buf, pos
+	}
+
+	common := cands[0]
+	for _, c := range cands[1:] {
+		for !strings.HasPrefix(c, common) {
+			common = common[:len(common)-1]
+		}
+	}
+	if len(common) > len(prefix) {
+		rest := []rune(common[len(prefix):])
+		buf = append(buf[:pos], append(rest, buf[pos:]...)...)
+		return buf, pos + len(rest)
+	}
+	if len(cands) > 1 {
+		fmt.Print("\r\n" + strings.Join(cands, "  ") + "\r\n")
+	}
+	return buf, pos
+}
+
+// search does reverse incremental search of history, done reports enter was pressed
+func (e *editor) search(line string) (string, bool) {
+	var query []rune
+	idx, match := len(e.history), line
+	find := func(from int) {
+		if from >= len(e.history) {
+			from = len(e.history) - 1
+		}
+		for i := from; i >= 0; i-- {
+			if strings.Contains(e.history[i], string(query)) {
+				idx, match = i, e.history[i]
+				return
+			}
+		}
+	}
+
+	for {
+		fmt.Printf("\r(reverse-i-search)`%s': %s\x1b[K", string(query), match)
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return line, false
+		}
+		switch {
+		case r == '\r' || r == '\n':
+			return match, true
+		case r == 18:
+			find(idx - 1)
+		case r == 127 || r == 8:
+			if len(query) > 0 {
+				query = query[:len(query)-1]
+				find(len(e.history) - 1)
+			}
+		case r == 3 || r == 7 || r == 27:
+			return line, false
+		case r >= ' ':
+			query = append(query, r)
+			find(idx)
+		default:
+			return match, false
+		}
+	}
+}
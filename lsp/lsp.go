@@ -0,0 +1,338 @@
+// Package lsp serves .vec sheets to editors with the Language Server Protocol.
+//
+// A sheet has one input per line, # starts a comment. Sheets holding
+// "$ input" lines are read as transcripts like vec test does, then only
+// those lines are inputs and ">> output" lines are skipped.
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/stetide/vector/vector"
+)
+
+// evalTimeout bounds evaluation of one line
+const evalTimeout = time.Second
+
+type position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type span struct {
+	Start position `json:"start"`
+	End   position `json:"end"`
+}
+
+type location struct {
+	URI   string `json:"uri"`
+	Range span   `json:"range"`
+}
+
+type diagnostic struct {
+	Range    span   `json:"range"`
+	Severity int    `json:"severity"`
+	Code     string `json:"code,omitempty"`
+	Source   string `json:"source"`
+	Message  string `json:"message"`
+}
+
+const (
+	severityError   = 1
+	severityWarning = 2
+)
+
+// completion item kinds of LSP
+var itemKinds = map[string]int{
+	"func":    3,
+	"var":     6,
+	"keyword": 14,
+	"const":   21,
+}
+
+// document is open sheet with session of its evaluated inputs
+type document struct {
+	lines  []string
+	inputs []vector.Input
+	sess   *vector.Session
+}
+
+// server keeps open documents
+type server struct {
+	conn *conn
+	docs map[string]*document
+}
+
+// Serve speaks LSP on r and w until client sends exit or r ends
+func Serve(r io.Reader, w io.Writer) error {
+	s := &server{conn: newConn(r, w), docs: map[string]*document{}}
+	for {
+		msg, err := s.conn.read()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if msg.Method == "exit" {
+			return nil
+		}
+		if err := s.handle(msg); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *server) handle(msg *message) error {
+	if msg.Error != nil {
+		return s.conn.write(&message{ID: msg.ID, Error: msg.Error})
+	}
+
+	var params struct {
+		TextDocument struct {
+			URI  string `json:"uri"`
+			Text string `json:"text"`
+		} `json:"textDocument"`
+		ContentChanges []struct {
+			Text string `json:"text"`
+		} `json:"contentChanges"`
+		Position position `json:"position"`
+	}
+	if len(msg.Params) > 0 {
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return s.reply(msg, nil, &rpcError{codeInvalidParams, err.Error()})
+		}
+	}
+	uri := params.TextDocument.URI
+
+	switch msg.Method {
+	case "initialize":
+		return s.reply(msg, map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"textDocumentSync":   1,
+				"hoverProvider":      true,
+				"completionProvider": map[string]interface{}{},
+				"definitionProvider": true,
+			},
+			"serverInfo": map[string]string{"name": "vec", "version": vector.VERSION},
+		}, nil)
+	case "shutdown":
+		return s.reply(msg, nil, nil)
+	case "textDocument/didOpen":
+		return s.update(uri, params.TextDocument.Text)
+	case "textDocument/didChange":
+		if n := len(params.ContentChanges); n > 0 {
+			return s.update(uri, params.ContentChanges[n-1].Text)
+		}
+		return nil
+	case "textDocument/didClose":
+		delete(s.docs, uri)
+		return s.conn.notify("textDocument/publishDiagnostics", map[string]interface{}{"uri": uri, "diagnostics": []diagnostic{}})
+	case "textDocument/hover":
+		return s.reply(msg, s.hover(uri, params.Position), nil)
+	case "textDocument/completion":
+		return s.reply(msg, s.complete(uri, params.Position), nil)
+	case "textDocument/definition":
+		return s.reply(msg, s.definition(uri, params.Position), nil)
+	}
+	if msg.ID != nil {
+		return s.reply(msg, nil, &rpcError{codeMethodNotFound, "Method not found: " + msg.Method})
+	}
+	return nil
+}
+
+// reply answers request msg, notifications get no answer
+func (s *server) reply(msg *message, result interface{}, err *rpcError) error {
+	if msg.ID == nil {
+		return nil
+	}
+	if result == nil && err == nil {
+		result = json.RawMessage("null")
+	}
+	return s.conn.write(&message{ID: msg.ID, Result: result, Error: err})
+}
+
+// update evaluates new text of document and publishes its diagnostics
+func (s *server) update(uri, text string) error {
+	doc := &document{lines: strings.Split(text, "\n"), sess: vector.NewSession()}
+	doc.inputs = vector.Inputs(text)
+	diags := []diagnostic{}
+	for _, in := range doc.inputs {
+		_, err := doc.sess.Parse(in.Text)
+		if list, ok := err.(vector.ErrorList); ok {
+			for _, e := range list {
+				diags = append(diags, doc.diagnose(in, e, severityError, len(in.Text), len(in.Text)))
+			}
+			continue
+		} else if err != nil {
+			diags = append(diags, doc.diagnose(in, err, severityError, len(in.Text), len(in.Text)))
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), evalTimeout)
+		_, err = doc.sess.Run(ctx, in.Text)
+		cancel()
+		if err != nil {
+			diags = append(diags, doc.diagnose(in, err, severityWarning, 0, len(in.Text)))
+		}
+	}
+	s.docs[uri] = doc
+	return s.conn.notify("textDocument/publishDiagnostics", map[string]interface{}{"uri": uri, "diagnostics": diags})
+}
+
+// diagnose describes err of in, start and end are used when err has no position
+func (d *document) diagnose(in vector.Input, err error, severity, start, end int) diagnostic {
+	diag := diagnostic{Severity: severity, Source: "vec", Message: err.Error()}
+	var verr vector.Error
+	if errors.As(err, &verr) {
+		diag.Code, diag.Message = verr.Code.String(), verr.Msg
+		if verr.Hint != "" {
+			diag.Message += "\nhint: " + verr.Hint
+		}
+		if verr.Span.Known() {
+			start, end = verr.Span.Start, verr.Span.End
+		}
+	}
+	diag.Range = d.span(in.Line, in.Off+start, in.Off+end)
+	return diag
+}
+
+// span returns range of bytes start to end of line
+func (d *document) span(line, start, end int) span {
+	return span{position{line, d.column(line, start)}, position{line, d.column(line, end)}}
+}
+
+// column converts byte offset in line to UTF-16 column used by LSP
+func (d *document) column(line, off int) int {
+	l := d.lines[line]
+	if off > len(l) {
+		off = len(l)
+	}
+	return len(utf16.Encode([]rune(l[:off])))
+}
+
+// offset converts position into byte offset in its line
+func (d *document) offset(p position) (string, int, bool) {
+	if p.Line < 0 || p.Line >= len(d.lines) {
+		return "", 0, false
+	}
+	l := d.lines[p.Line]
+	col := 0
+	for i, r := range l {
+		if col >= p.Character {
+			return l, i, true
+		}
+		col += len(utf16.Encode([]rune{r}))
+	}
+	return l, len(l), true
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// word returns name around position and byte offsets of it
+func (d *document) word(p position) (string, int, int) {
+	l, off, ok := d.offset(p)
+	if !ok {
+		return "", 0, 0
+	}
+	start, end := off, off
+	for start > 0 && isWordByte(l[start-1]) {
+		start--
+	}
+	for end < len(l) && isWordByte(l[end]) {
+		end++
+	}
+	return l[start:end], start, end
+}
+
+func (s *server) hover(uri string, p position) interface{} {
+	doc := s.docs[uri]
+	if doc == nil {
+		return nil
+	}
+	name, start, end := doc.word(p)
+	if name == "" {
+		return nil
+	}
+
+	var text string
+	switch doc.sess.Kind(name) {
+	case "keyword":
+		text = "keyword `" + name + "`"
+	case "func":
+		text = "function `" + name + "`"
+	case "var", "const":
+		for _, v := range doc.sess.Vars() {
+			if v.Name != name {
+				continue
+			}
+			if v.Const {
+				text = "```\n" + name + " = " + v.Value + "\n```\nconstant " + v.Type
+			} else {
+				text = "```\n" + name + " = " + v.Expression + "\n```\nvalue: `" + v.Value + "` " + v.Type
+			}
+		}
+	default:
+		return nil
+	}
+	return map[string]interface{}{
+		"contents": map[string]string{"kind": "markdown", "value": text},
+		"range":    doc.span(p.Line, start, end),
+	}
+}
+
+func (s *server) complete(uri string, p position) interface{} {
+	doc := s.docs[uri]
+	items := []map[string]interface{}{}
+	if doc == nil {
+		return items
+	}
+	l, off, _ := doc.offset(p)
+	start := off
+	for start > 0 && isWordByte(l[start-1]) {
+		start--
+	}
+	for _, name := range doc.sess.Complete(l[start:off]) {
+		item := map[string]interface{}{"label": name}
+		if kind, ok := itemKinds[doc.sess.Kind(name)]; ok {
+			item["kind"] = kind
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// definition finds last assignment of name before position, or first one after it
+func (s *server) definition(uri string, p position) interface{} {
+	doc := s.docs[uri]
+	if doc == nil {
+		return nil
+	}
+	name, _, _ := doc.word(p)
+	if name == "" {
+		return nil
+	}
+
+	var found *location
+	for _, in := range doc.inputs {
+		assigned, pos, ok := vector.Assigned(in.Text)
+		if !ok || assigned != name {
+			continue
+		}
+		if found != nil && in.Line > p.Line {
+			break
+		}
+		off := in.Off + pos
+		found = &location{URI: uri, Range: doc.span(in.Line, off, off+len(name))}
+	}
+	if found == nil {
+		return nil
+	}
+	return found
+}
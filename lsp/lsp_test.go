@@ -0,0 +1,141 @@
+package lsp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const sheet = `a = 1
+b = a + 2
+# a = 10
+a = 3
+c = a * b + sin(pi)
+format digits 3`
+
+// open returns server holding sheet as doc.vec
+func open(t *testing.T, text string) *server {
+	t.Helper()
+	s := &server{conn: newConn(strings.NewReader(""), ioutil.Discard), docs: map[string]*document{}}
+	if err := s.update("doc.vec", text); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestDefinition(t *testing.T) {
+	s := open(t, sheet)
+	tests := []struct {
+		line, char int
+		want       *location
+	}{
+		{1, 4, &location{"doc.vec", span{position{0, 0}, position{0, 1}}}},
+		{4, 4, &location{"doc.vec", span{position{3, 0}, position{3, 1}}}},
+		{4, 9, &location{"doc.vec", span{position{1, 0}, position{1, 1}}}},
+		{0, 0, &location{"doc.vec", span{position{0, 0}, position{0, 1}}}},
+		{4, 13, nil},
+		{1, 3, nil},
+		{9, 0, nil},
+	}
+	for _, tt := range tests {
+		got := s.definition("doc.vec", position{tt.line, tt.char})
+		if tt.want == nil {
+			if got != nil {
+				t.Errorf("definition(%d:%d) = %v, want nil", tt.line, tt.char, got)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("definition(%d:%d) = %v, want %v", tt.line, tt.char, got, tt.want)
+		}
+	}
+	if got := s.definition("other.vec", position{0, 0}); got != nil {
+		t.Errorf("definition in unknown document = %v, want nil", got)
+	}
+}
+
+func TestDefinitionTranscript(t *testing.T) {
+	s := open(t, "$ x = 2\n>> 2\n$ y = x ^ 2\n>> 4")
+	want := &location{"doc.vec", span{position{0, 2}, position{0, 3}}}
+	if got := s.definition("doc.vec", position{2, 6}); !reflect.DeepEqual(got, want) {
+		t.Errorf("definition = %v, want %v", got, want)
+	}
+}
+
+func TestHover(t *testing.T) {
+	s := open(t, sheet)
+	tests := []struct {
+		line, char int
+		want       string
+		start, end int
+	}{
+		{4, 4, "```\na = 3\n```\nvalue: `3` num", 4, 5},
+		{4, 0, "```\nc = a * b + sin(pi)\n```\nvalue: `15` num", 0, 1},
+		{4, 14, "function `sin`", 12, 15},
+		{4, 17, "```\npi = 3.14\n```\nconstant num", 16, 18},
+		{5, 2, "keyword `format`", 0, 6},
+		{1, 3, "", 0, 0},
+	}
+	for _, tt := range tests {
+		got := s.hover("doc.vec", position{tt.line, tt.char})
+		if tt.want == "" {
+			if got != nil {
+				t.Errorf("hover(%d:%d) = %v, want nil", tt.line, tt.char, got)
+			}
+			continue
+		}
+		want := map[string]interface{}{
+			"contents": map[string]string{"kind": "markdown", "value": tt.want},
+			"range":    span{position{tt.line, tt.start}, position{tt.line, tt.end}},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("hover(%d:%d) = %v, want %v", tt.line, tt.char, got, want)
+		}
+	}
+}
+
+func TestServe(t *testing.T) {
+	var in bytes.Buffer
+	send := func(id int, method string, params interface{}) {
+		msg := map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params}
+		if id > 0 {
+			msg["id"] = id
+		}
+		body, _ := json.Marshal(msg)
+		fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	}
+	doc := map[string]string{"uri": "doc.vec"}
+	send(0, "textDocument/didOpen", map[string]interface{}{"textDocument": map[string]string{"uri": "doc.vec", "text": "a = 1\nb = a +"}})
+	send(1, "textDocument/definition", map[string]interface{}{"textDocument": doc, "position": position{1, 4}})
+	send(2, "textDocument/hover", map[string]interface{}{"textDocument": doc, "position": position{0, 0}})
+	send(0, "exit", nil)
+
+	var out bytes.Buffer
+	if err := Serve(&in, &out); err != nil {
+		t.Fatal(err)
+	}
+	c := newConn(&out, nil)
+	var msgs []*message
+	for {
+		msg, err := c.read()
+		if err != nil {
+			break
+		}
+		msgs = append(msgs, msg)
+	}
+	if len(msgs) != 3 {
+		t.Fatalf("got %d messages, want 3", len(msgs))
+	}
+	if msgs[0].Method != "textDocument/publishDiagnostics" || !strings.Contains(string(msgs[0].Params), `"code":"E`) {
+		t.Errorf("diagnostics = %s", msgs[0].Params)
+	}
+	for i, want := range []string{`"end":{"character":1,"line":0},"start":{"character":0,"line":0}`, "value: `1` num"} {
+		if res, _ := json.Marshal(msgs[i+1].Result); !strings.Contains(string(res), want) {
+			t.Errorf("reply %d = %s, want %s in it", i+1, res, want)
+		}
+	}
+}
@@ -0,0 +1,297 @@
+// Package server evaluates vector input over HTTP with JSON bodies.
+//
+//	POST   /sessions             create session, returns {"id": ...}
+//	DELETE /sessions/{id}        drop session
+//	POST   /sessions/{id}/eval   evaluate {"input": ...}
+//	GET    /sessions/{id}/vars   list variables and constants
+//	GET    /sessions/{id}/export variables as assignments
+//
+// Failures answer {"error": {"type": "syntax", "message": ...}}, errors of
+// evaluation add "code" like "E101", "hint" and "span" {"start", "end"} in input.
+// Several syntax errors are all in "errors", "error" describes first of them.
+// Type is category of error code like syntax or math, or for requests not
+// evaluated one of not_found, method, request, limit and internal.
+//
+// Sessions idle longer than IdleTimeout of Config are dropped.
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stetide/vector/vector"
+)
+
+// Config bounds resources used by requests
+type Config struct {
+	// Timeout bounds one evaluation
+	Timeout time.Duration
+	// MaxBody bounds request body in bytes
+	MaxBody int64
+	// MaxInput bounds length of input in bytes
+	MaxInput int
+	// MaxSessions bounds number of open sessions
+	MaxSessions int
+	// IdleTimeout drops sessions unused for that long
+	IdleTimeout time.Duration
+}
+
+// DefaultConfig is used for zero fields of Config
+var DefaultConfig = Config{
+	Timeout:     2 * time.Second,
+	MaxBody:     64 << 10,
+	MaxInput:    4 << 10,
+	MaxSessions: 1000,
+	IdleTimeout: 30 * time.Minute,
+}
+
+// types of errors not coming from evaluation
+const (
+	errNotFound = "not_found"
+	errMethod   = "method"
+	errRequest  = "request"
+	errLimit    = "limit"
+	errInternal = "internal"
+)
+
+// Server serves sessions, each with own variables and settings
+type Server struct {
+	cfg      Config
+	mu       sync.Mutex
+	sessions map[string]*entry
+	// now is clock of idle timeout
+	now func() time.Time
+}
+
+// entry is session with time of last request
+type entry struct {
+	sess *vector.Session
+	used time.Time
+}
+
+// New returns Server limited by cfg
+func New(cfg Config) *Server {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultConfig.Timeout
+	}
+	if cfg.MaxBody <= 0 {
+		cfg.MaxBody = DefaultConfig.MaxBody
+	}
+	if cfg.MaxInput <= 0 {
+		cfg.MaxInput = DefaultConfig.MaxInput
+	}
+	if cfg.MaxSessions <= 0 {
+		cfg.MaxSessions = DefaultConfig.MaxSessions
+	}
+	if cfg.IdleTimeout <= 0 {
+		cfg.IdleTimeout = DefaultConfig.IdleTimeout
+	}
+	return &Server{cfg: cfg, sessions: map[string]*entry{}, now: time.Now}
+}
+
+// apiError is error answered to client
+type apiError struct {
+	status int
+	Type   string       `json:"type"`
+	Msg    string       `json:"message"`
+	Code   string       `json:"code,omitempty"`
+	Hint   string       `json:"hint,omitempty"`
+	Span   *vector.Span `json:"span,omitempty"`
+	Errors []apiError   `json:"errors,omitempty"`
+}
+
+func (e apiError) Error() string {
+	return e.Msg
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if parts[0] != "sessions" || len(parts) > 3 {
+		s.fail(w, apiError{status: http.StatusNotFound, Type: errNotFound, Msg: "Unknown path " + r.URL.Path})
+		return
+	}
+
+	route := r.Method + " sessions"
+	if len(parts) > 1 {
+		route += "/id"
+	}
+	if len(parts) > 2 {
+		route += "/" + parts[2]
+	}
+
+	var sess *vector.Session
+	if len(parts) > 1 {
+		sess = s.session(parts[1])
+		if sess == nil {
+			s.fail(w, apiError{status: http.StatusNotFound, Type: errNotFound, Msg: "Unknown session " + parts[1]})
+			return
+		}
+	}
+
+	switch route {
+	case "POST sessions":
+		s.create(w)
+	case "DELETE sessions/id":
+		s.mu.Lock()
+		delete(s.sessions, parts[1])
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	case "POST sessions/id/eval":
+		s.eval(w, r, sess)
+	case "GET sessions/id/vars":
+		var vars []variable
+		for _, v := range sess.Vars() {
+			vars = append(vars, variable(v))
+		}
+		s.reply(w, http.StatusOK, map[string]interface{}{"vars": vars})
+	case "GET sessions/id/export":
+		s.reply(w, http.StatusOK, map[string]string{"export": sess.Export()})
+	default:
+		s.fail(w, apiError{status: http.StatusMethodNotAllowed, Type: errMethod, Msg: r.Method + " not allowed for " + r.URL.Path})
+	}
+}
+
+// variable is vector.Var with JSON names
+type variable struct {
+	Name       string `json:"name"`
+	Expression string `json:"expression,omitempty"`
+	Value      string `json:"value"`
+	Type       string `json:"type"`
+	Const      bool   `json:"const,omitempty"`
+}
+
+func (s *Server) create(w http.ResponseWriter) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		s.fail(w, apiError{status: http.StatusInternalServerError, Type: errInternal, Msg: err.Error()})
+		return
+	}
+	id := hex.EncodeToString(b[:])
+
+	s.mu.Lock()
+	s.evict()
+	if len(s.sessions) >= s.cfg.MaxSessions {
+		s.mu.Unlock()
+		s.fail(w, apiError{status: http.StatusServiceUnavailable, Type: errLimit, Msg: "Too many sessions"})
+		return
+	}
+	s.sessions[id] = &entry{sess: vector.NewSession(), used: s.now()}
+	s.mu.Unlock()
+	s.reply(w, http.StatusCreated, map[string]string{"id": id})
+}
+
+// session returns session id and marks it used, nil if unknown or idle too long
+func (s *Server) session(id string) *vector.Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := s.sessions[id]
+	if e == nil {
+		return nil
+	}
+	now := s.now()
+	if now.Sub(e.used) > s.cfg.IdleTimeout {
+		delete(s.sessions, id)
+		return nil
+	}
+	e.used = now
+	return e.sess
+}
+
+// evict drops sessions idle too long, s.mu must be held
+func (s *Server) evict() {
+	now := s.now()
+	for id, e := range s.sessions {
+		if now.Sub(e.used) > s.cfg.IdleTimeout {
+			delete(s.sessions, id)
+		}
+	}
+}
+
+func (s *Server) eval(w http.ResponseWriter, r *http.Request, sess *vector.Session) {
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, s.cfg.MaxBody))
+	if err != nil {
+		s.fail(w, apiError{status: http.StatusRequestEntityTooLarge, Type: errLimit, Msg: fmt.Sprintf("Body exceeds %d bytes", s.cfg.MaxBody)})
+		return
+	}
+	var req struct {
+		Input string `json:"input"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		s.fail(w, apiError{status: http.StatusBadRequest, Type: errRequest, Msg: "Invalid JSON: " + err.Error()})
+		return
+	}
+	if len(req.Input) > s.cfg.MaxInput {
+		s.fail(w, apiError{status: http.StatusRequestEntityTooLarge, Type: errLimit, Msg: fmt.Sprintf("Input exceeds %d bytes", s.cfg.MaxInput)})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.cfg.Timeout)
+	defer cancel()
+	res, err := sess.Run(ctx, req.Input)
+	if err != nil {
+		s.fail(w, evalError(err))
+		return
+	}
+	switch cmd := res.(type) {
+	case nil:
+		s.reply(w, http.StatusOK, map[string]string{})
+		return
+	case vector.Command:
+		switch cmd.Kind {
+		case vector.CmdOutput, vector.CmdHelp:
+			s.reply(w, http.StatusOK, map[string]string{"output": cmd.Text})
+		default:
+			s.reply(w, http.StatusOK, map[string]string{})
+		}
+		return
+	}
+	s.reply(w, http.StatusOK, map[string]string{"result": sess.Show(res), "type": vector.TypeName(res)})
+}
+
+// evalError maps error of evaluation to status, type is category of error like syntax
+func evalError(err error) apiError {
+	if list, ok := err.(vector.ErrorList); ok {
+		e := evalError(list[0])
+		for _, verr := range list {
+			e.Errors = append(e.Errors, evalError(verr))
+		}
+		return e
+	}
+	e := apiError{status: http.StatusUnprocessableEntity, Type: errInternal, Msg: err.Error()}
+	var verr vector.Error
+	if !errors.As(err, &verr) {
+		return e
+	}
+	e.Type, e.Msg, e.Code, e.Hint = verr.Category().String(), verr.Msg, verr.Code.String(), verr.Hint
+	if verr.Span.Known() {
+		e.Span = &verr.Span
+	}
+	switch {
+	case verr.Category() == vector.CatSyntax:
+		e.status = http.StatusBadRequest
+	case verr.Code == vector.ErrCanceled:
+		e.status = http.StatusRequestTimeout
+	}
+	return e
+}
+
+func (s *Server) reply(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.Encode(v)
+}
+
+func (s *Server) fail(w http.ResponseWriter, e apiError) {
+	s.reply(w, e.status, map[string]apiError{"error": e})
+}
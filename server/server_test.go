@@ -0,0 +1,83 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// do sends request to srv and decodes answer into map
+func do(t *testing.T, srv *Server, method, path, body string) (int, map[string]interface{}) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+	res := map[string]interface{}{}
+	if rec.Body.Len() > 0 {
+		if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+			t.Fatalf("%s %s: %v", method, path, err)
+		}
+	}
+	return rec.Code, res
+}
+
+func create(t *testing.T, srv *Server) string {
+	t.Helper()
+	status, res := do(t, srv, "POST", "/sessions", "")
+	if status != http.StatusCreated {
+		t.Fatalf("create = %d %v", status, res)
+	}
+	return res["id"].(string)
+}
+
+func TestErrorTypes(t *testing.T) {
+	srv := New(Config{MaxInput: 8})
+	id := create(t, srv)
+	tests := []struct {
+		method, path, body string
+		status             int
+		typ                string
+	}{
+		{"GET", "/other", "", http.StatusNotFound, "not_found"},
+		{"GET", "/sessions/nope/vars", "", http.StatusNotFound, "not_found"},
+		{"PUT", "/sessions/" + id + "/eval", "", http.StatusMethodNotAllowed, "method"},
+		{"POST", "/sessions/" + id + "/eval", "{", http.StatusBadRequest, "request"},
+		{"POST", "/sessions/" + id + "/eval", `{"input": "1 + 2 + 3 + 4"}`, http.StatusRequestEntityTooLarge, "limit"},
+		{"POST", "/sessions/" + id + "/eval", `{"input": "1 +"}`, http.StatusBadRequest, "syntax"},
+		{"POST", "/sessions/" + id + "/eval", `{"input": "1 / 0"}`, http.StatusUnprocessableEntity, "math"},
+	}
+	for _, tt := range tests {
+		status, res := do(t, srv, tt.method, tt.path, tt.body)
+		e, _ := res["error"].(map[string]interface{})
+		if status != tt.status || e["type"] != tt.typ {
+			t.Errorf("%s %s %s = %d %v, want %d %s", tt.method, tt.path, tt.body, status, e["type"], tt.status, tt.typ)
+		}
+	}
+}
+
+func TestIdleTimeout(t *testing.T) {
+	now := time.Unix(0, 0)
+	srv := New(Config{MaxSessions: 2, IdleTimeout: time.Minute})
+	srv.now = func() time.Time { return now }
+
+	idle, busy := create(t, srv), create(t, srv)
+	if status, _ := do(t, srv, "POST", "/sessions", ""); status != http.StatusServiceUnavailable {
+		t.Fatalf("third session = %d, want %d", status, http.StatusServiceUnavailable)
+	}
+	now = now.Add(50 * time.Second)
+	do(t, srv, "POST", "/sessions/"+busy+"/eval", `{"input": "a = 1"}`)
+	now = now.Add(50 * time.Second)
+
+	if status, _ := do(t, srv, "GET", "/sessions/"+idle+"/vars", ""); status != http.StatusNotFound {
+		t.Errorf("idle session = %d, want %d", status, http.StatusNotFound)
+	}
+	if status, _ := do(t, srv, "GET", "/sessions/"+busy+"/vars", ""); status != http.StatusOK {
+		t.Errorf("used session = %d, want %d", status, http.StatusOK)
+	}
+	// evicting idle sessions makes room
+	now = now.Add(2 * time.Minute)
+	create(t, srv)
+	create(t, srv)
+}
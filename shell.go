@@ -1,75 +1,231 @@
 package main
 
 import (
-	"bufio"
+	"errors"
+	"flag"
 	"fmt"
+	"net/http"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/stetide/vector/lsp"
+	"github.com/stetide/vector/server"
 	"github.com/stetide/vector/vector"
 )
 
-func input(msg string) string {
-	fmt.Print(msg)
-	r := bufio.NewReader(os.Stdin)
-	txt, _ := r.ReadString('\n')
-	return txt
-}
-
 func push(a interface{}) {
 	fmt.Println(">>", a)
 }
 
+// errText returns err with hint on following line, each error of list starts at its column or end
+func errText(err error) string {
+	if list, ok := err.(vector.ErrorList); ok {
+		var lines []string
+		for _, e := range list {
+			// errors without position are at end of input
+			col := "end"
+			if e.Span.Known() {
+				col = fmt.Sprintf("col %d", e.Span.Start+1)
+			}
+			lines = append(lines, col+": "+errText(e))
+		}
+		return strings.Join(lines, "\n")
+	}
+	var verr vector.Error
+	if errors.As(err, &verr) && verr.Hint != "" {
+		return err.Error() + "\nhint: " + verr.Hint
+	}
+	return err.Error()
+}
+
 func main() {
-	if len(os.Args[1:]) > 0 {
-		txt := strings.TrimSpace(strings.Join(os.Args[1:], " "))
+	var render, dump func(vector.Node) string
+	args := os.Args[1:]
+	for len(args) > 0 && strings.HasPrefix(args[0], "--") {
+		var err error
+		flag := strings.SplitN(args[0], "=", 2)
+		switch flag[0] {
+		case "--dump-ast":
+			switch strings.Join(flag[1:], "") {
+			case "json":
+				dump = vector.JSON
+			case "dot":
+				dump = vector.DOT
+			default:
+				err = fmt.Errorf("Expected --dump-ast=json or --dump-ast=dot")
+			}
+		case "--trace":
+			vector.SetTrace(os.Stdout)
+		case "--latex":
+			render = vector.LaTeX
+		case "--mathml":
+			render = vector.MathML
+		case "--frac", "--group":
+			err = vector.SetFormat(flag[0][2:], "on")
+		case "--digits":
+			err = vector.SetFormat(append([]string{"digits"}, flag[1:]...)...)
+		case "--notation":
+			err = vector.SetFormat(flag[1:]...)
+		case "--lang":
+			err = vector.SetLang(strings.Join(flag[1:], ""))
+		default:
+			err = fmt.Errorf("Unknown flag: %s", args[0])
+		}
+		if err != nil {
+			fmt.Println(errText(err))
+			return
+		}
+		args = args[1:]
+	}
+
+	if len(args) > 0 && args[0] == "serve" {
+		fs := flag.NewFlagSet("serve", flag.ExitOnError)
+		addr := fs.String("addr", "localhost:8080", "address to listen on")
+		cfg := server.DefaultConfig
+		fs.DurationVar(&cfg.Timeout, "timeout", cfg.Timeout, "time limit of one evaluation")
+		fs.DurationVar(&cfg.IdleTimeout, "idle", cfg.IdleTimeout, "drop sessions unused for this long")
+		fs.Parse(args[1:])
+		fmt.Println("VECTOR " + vector.VERSION + " serving on " + *addr)
+		srv := &http.Server{
+			Addr:    *addr,
+			Handler: server.New(cfg),
+			// evaluation is bounded by cfg.Timeout, slow clients by these
+			ReadTimeout:  10 * time.Second,
+			WriteTimeout: cfg.Timeout + 10*time.Second,
+			IdleTimeout:  2 * time.Minute,
+		}
+		fmt.Println(srv.ListenAndServe())
+		os.Exit(1)
+	}
+
+	if len(args) > 0 && args[0] == "lsp" {
+		if err := lsp.Serve(os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if len(args) > 0 && args[0] == "check" {
+		if len(args) == 1 {
+			fmt.Println("Usage: vec check 'file' ...")
+			os.Exit(2)
+		}
+		if !runCheck(args[1:]) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if len(args) > 0 && args[0] == "test" {
+		if len(args) == 1 {
+			fmt.Println("Usage: vec test 'file' ...")
+			os.Exit(2)
+		}
+		if !runTests(args[1:], render) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if len(args) > 0 {
+		txt := strings.TrimSpace(strings.Join(args, " "))
+		if render != nil || dump != nil {
+			ast, err := vector.Parse(txt)
+			if err != nil {
+				fmt.Println(errText(err))
+				return
+			}
+			if dump != nil {
+				fmt.Println(dump(ast))
+			}
+			if render == nil {
+				render = vector.Node.String
+			} else {
+				fmt.Println(render(ast))
+			}
+			res, err := vector.Execute(ast)
+			if err != nil {
+				fmt.Println(errText(err))
+				return
+			}
+			if cmd, ok := res.(vector.Command); ok {
+				fmt.Println(cmd.Text)
+			} else if res != nil {
+				fmt.Println(render(res))
+			}
+			return
+		}
 		res, err := vector.Run(txt)
 		if err != nil {
-			fmt.Println(err)
+			fmt.Println(errText(err))
 			return
 		}
-		fmt.Println(res)
+		if res != nil {
+			fmt.Println(vector.Show(res))
+		}
 		return
 	}
 
 	fmt.Println("VECTOR " + vector.VERSION)
+	ed := newEditor()
 	for {
-		txt := input("$ ")
+		txt, err := ed.readLine("$ ")
+		if err != nil {
+			return
+		}
 		txt = strings.TrimSpace(txt)
 		if txt == "" {
 			continue
 		}
 
-		lexer := vector.NewLexer(txt)
-		tokens, err := lexer.GenerateTokens()
+		ast, err := vector.Parse(txt)
+		for err != nil {
+			// only input missing its end may go on, lists hold other errors
+			verr, ok := err.(vector.Error)
+			if !ok || verr.Code != vector.ErrIncomplete {
+				break
+			}
+			// continue input until expression is complete, empty line gives up
+			more, rerr := ed.readLine("... ")
+			if more = strings.TrimSpace(more); rerr != nil || more == "" {
+				break
+			}
+			txt += " " + more
+			ast, err = vector.Parse(txt)
+		}
 		if err != nil {
-			push(err)
+			push(errText(err))
 			continue
 		}
-		// log.Println(tokens)
+		if dump != nil {
+			fmt.Println(dump(ast))
+		}
 
-		parser := vector.NewParser(tokens)
-		ast, err := parser.Parse()
+		res, err := vector.Execute(ast)
 		if err != nil {
-			switch err.(type) {
-			case vector.ExitErr:
+			push(errText(err))
+			continue
+		}
+		switch cmd := res.(type) {
+		case nil:
+			continue
+		case vector.Command:
+			switch cmd.Kind {
+			case vector.CmdExit:
 				return
-			case vector.ClearErr:
-				err.(vector.ClearErr).Clear()
-				continue
+			case vector.CmdClear:
+				clearScreen()
+			default:
+				push(cmd.Text)
 			}
-			push(err)
 			continue
 		}
-		fmt.Println(ast)
-
-		res, err := vector.Execute(ast)
-		if err != nil {
-			push(err)
-			continue
-		} else if res == nil {
+		if render != nil {
+			push(render(res))
 			continue
 		}
-		push(res)
+		push(vector.Show(res))
 	}
 }
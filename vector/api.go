@@ -0,0 +1,279 @@
+package vector
+
+import "context"
+
+// Value is evaluation result usable from Go
+type Value interface {
+	String() string
+	node() Node
+}
+
+// Number is number value
+type Number float64
+
+// Float returns n as float64
+func (n Number) Float() float64 {
+	return float64(n)
+}
+
+func (n Number) String() string {
+	return NumberNode(n).String()
+}
+
+func (n Number) node() Node {
+	return NumberNode(n)
+}
+
+// Bool is result of comparison
+type Bool bool
+
+func (b Bool) String() string {
+	return BoolNode(b).String()
+}
+
+func (b Bool) node() Node {
+	return BoolNode(b)
+}
+
+// Vector is vector value
+type Vector struct {
+	comps []float64
+}
+
+// NewVector returns Vector of comps
+func NewVector(comps ...float64) Vector {
+	return Vector{append([]float64(nil), comps...)}
+}
+
+// Len returns number of components
+func (v Vector) Len() int {
+	return len(v.comps)
+}
+
+// At returns component i
+func (v Vector) At(i int) float64 {
+	return v.comps[i]
+}
+
+// Components returns copy of components
+func (v Vector) Components() []float64 {
+	return append([]float64(nil), v.comps...)
+}
+
+func (v Vector) String() string {
+	return v.node().String()
+}
+
+func (v Vector) node() Node {
+	var vec VecNode
+	for _, c := range v.comps {
+		vec.fields = append(vec.fields, NumberNode(c))
+	}
+	return vec
+}
+
+// List is list of values
+type List struct {
+	items []Value
+}
+
+// NewList returns List of items
+func NewList(items ...Value) List {
+	return List{append([]Value(nil), items...)}
+}
+
+// Len returns number of items
+func (l List) Len() int {
+	return len(l.items)
+}
+
+// At returns item i
+func (l List) At(i int) Value {
+	return l.items[i]
+}
+
+func (l List) String() string {
+	return l.node().String()
+}
+
+func (l List) node() Node {
+	var list ListNode
+	for _, it := range l.items {
+		list.items = append(list.items, it.node())
+	}
+	return list
+}
+
+// Func is function value like x -> x ^ 2
+type Func struct {
+	fn LambdaNode
+	// s is evaluation which made f, nil for std
+	s *state
+}
+
+// Params returns parameter names
+func (f Func) Params() []string {
+	return append([]string(nil), f.fn.params...)
+}
+
+// Call applies f to args in session f came from
+func (f Func) Call(args ...Value) (Value, error) {
+	nodes := make([]Node, len(args))
+	for i, a := range args {
+		nodes[i] = a.node()
+	}
+	// f was passed to registered function, its evaluation holds the lock
+	if f.s != nil && f.s.host > 0 {
+		return f.call(f.s, nodes)
+	}
+	sess := std
+	if f.s != nil {
+		sess = f.s.sess
+	}
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	return f.call(newState(context.Background(), sess), nodes)
+}
+
+func (f Func) call(s *state, args []Node) (Value, error) {
+	res, err := f.fn.call(s, args)
+	if err != nil {
+		return nil, err
+	}
+	return valueOf(s, res)
+}
+
+func (f Func) String() string {
+	return Format(f.fn)
+}
+
+func (f Func) node() Node {
+	return f.fn
+}
+
+// TypeName returns type of value n like num, vec, list, bool or func
+func TypeName(n Node) string {
+	return typeName(n)
+}
+
+// ValueOf converts node into Value
+func ValueOf(n Node) (Value, error) {
+	std.mu.Lock()
+	defer std.mu.Unlock()
+	return valueOf(newState(context.Background(), std), n)
+}
+
+func valueOf(s *state, n Node) (Value, error) {
+	if n == nil {
+		return nil, nil
+	}
+	n, err := s.resolve(n)
+	if err != nil {
+		return nil, err
+	}
+	switch n := n.(type) {
+	case NumberNode:
+		return Number(n), nil
+	case BoolNode:
+		return Bool(n), nil
+	case VecNode:
+		var v Vector
+		for _, f := range n.fields {
+			v.comps = append(v.comps, float64(f.(NumberNode)))
+		}
+		return v, nil
+	case ListNode:
+		var l List
+		for _, it := range n.items {
+			v, err := valueOf(s, it)
+			if err != nil {
+				return nil, err
+			}
+			l.items = append(l.items, v)
+		}
+		return l, nil
+	case LambdaNode:
+		return Func{n, s}, nil
+	}
+	return nil, newErr(ErrNoValue, Format(n))
+}
+
+// Eval runs src and returns its Value
+func Eval(src string) (Value, error) {
+	res, err := Run(src)
+	if err != nil {
+		return nil, err
+	}
+	return ValueOf(res)
+}
+
+// isName reports if str can name variable or function
+func isName(str string) bool {
+	if str == "" || isKeyword(str) {
+		return false
+	}
+	for i, c := range str {
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i > 0 && c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// SetVar stores v as variable name
+func SetVar(name string, v Value) error {
+	if !isName(name) || isFunc(name) {
+		return newErr(ErrVarName, name)
+	} else if _, ok := constants[name]; ok {
+		return newErr(ErrConstant, name)
+	}
+	std.mu.Lock()
+	defer std.mu.Unlock()
+	std.store(name, v.node(), nil)
+	return nil
+}
+
+// GetVar returns value of variable name
+func GetVar(name string) (Value, error) {
+	return ValueOf(VarNode{ident: Token{ttype: tIDENT, val: name}})
+}
+
+// RegisterFunc makes fn callable as name with arity arguments, arity -1 accepts any count.
+// Built-in functions, constants and keywords cannot be replaced. fn runs while its
+// session is locked, it may call Funcs among args but no other function of the package
+func RegisterFunc(name string, arity int, fn func(args ...Value) (Value, error)) error {
+	if !isName(name) {
+		return newErr(ErrFuncName, name)
+	} else if _, ok := constants[name]; ok {
+		return newErr(ErrConstant, name)
+	}
+	funcMu.Lock()
+	defer funcMu.Unlock()
+	if _, ok := functions[function(name)]; ok && !hostFuncs[function(name)] {
+		return newErr(ErrBuiltin, name)
+	}
+	hostFuncs[function(name)] = true
+	functions[function(name)] = funcDef{arity: arity, call: func(s *state, args []Node) (Node, error) {
+		vals := make([]Value, len(args))
+		for i, a := range args {
+			v, err := valueOf(s, a)
+			if err != nil {
+				return nil, err
+			}
+			vals[i] = v
+		}
+		s.host++
+		res, err := fn(vals...)
+		s.host--
+		if err != nil {
+			return nil, err
+		}
+		if res == nil {
+			return nil, newErr(ErrNoResult, name)
+		}
+		return res.node(), nil
+	}}
+	// stored formulas may call name
+	funcGen++
+	return nil
+}
@@ -0,0 +1,143 @@
+package vector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestRegisterFunc(t *testing.T) {
+	double := func(args ...Value) (Value, error) {
+		return Number(args[0].(Number) * 2), nil
+	}
+	tests := []struct {
+		name string
+		err  Code
+	}{
+		{"twice", 0},
+		{"twice", 0},
+		{"sin", ErrBuiltin},
+		{"map", ErrBuiltin},
+		{"if", ErrBuiltin},
+		{"pi", ErrConstant},
+		{"e", ErrConstant},
+		{"vec", ErrFuncName},
+		{"2x", ErrFuncName},
+	}
+	for _, tt := range tests {
+		err := RegisterFunc(tt.name, 1, double)
+		if tt.err == 0 && err != nil || tt.err != 0 && !errors.Is(err, tt.err) {
+			t.Errorf("RegisterFunc(%q) = %v, want %v", tt.name, err, tt.err)
+		}
+	}
+	if v, err := Eval("twice(21) + sin(0)"); err != nil || v.String() != "42" {
+		t.Errorf("twice(21) + sin(0) = %v %v, want 42", v, err)
+	}
+}
+
+func TestRegisterFuncConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				RegisterFunc("inc", 1, func(args ...Value) (Value, error) {
+					return Number(args[0].(Number) + 1), nil
+				})
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			sess := NewSession()
+			for j := 0; j < 50; j++ {
+				sess.Run(context.Background(), "sin(1) + len({1; 2})")
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestFuncCallInRegistered(t *testing.T) {
+	err := RegisterFunc("apply", 2, func(args ...Value) (Value, error) {
+		f, ok := args[0].(Func)
+		if !ok {
+			return nil, errors.New("apply expects function")
+		}
+		return f.Call(args[1])
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err := Eval("apply(x -> x * 3; 5)"); err != nil || v.String() != "15" {
+		t.Errorf("apply(x -> x * 3; 5) = %v %v, want 15", v, err)
+	}
+	v, err := Eval("y -> y + 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res, err := v.(Func).Call(Number(1)); err != nil || res.String() != "2" {
+		t.Errorf("(y -> y + 1)(1) = %v %v, want 2", res, err)
+	}
+}
+
+// TestConcurrentAPI is meant for go test -race
+func TestConcurrentAPI(t *testing.T) {
+	p, err := Compile("x * 2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		name := fmt.Sprintf("conc%d", i)
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if err := SetVar(name, Number(j)); err != nil {
+					t.Error(err)
+					return
+				}
+				if _, err := GetVar(name); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				v, err := Eval("z -> z ^ 2")
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				v.(Func).Call(Number(j))
+				// String ignores format of std
+				if got := (VecNode{fields: []Node{NumberNode(1.0 / 3)}}).String(); got != "vec(0.333333333333333)" {
+					t.Errorf("String() = %q while format changes", got)
+					return
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				SetFormat("digits", "3")
+				Compile("1 + 2")
+				SetFormat("digits", "15")
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for j := 0; j < 50; j++ {
+			p.Set("x", NumberNode(j))
+			p.Run()
+		}
+	}()
+	wg.Wait()
+}
@@ -0,0 +1,130 @@
+package vector
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// tolerance bounds difference of approximately equal numbers
+type tolerance struct {
+	abs float64
+	rel float64
+}
+
+var defaultEpsilon = tolerance{abs: 1e-9, rel: 1e-9}
+
+func (t tolerance) String() string {
+	return fmt.Sprintf("abs %g, rel %g", t.abs, t.rel)
+}
+
+// SetEpsilon changes default tolerance like the eps keyword, e.g. SetEpsilon("abs", "0.001")
+func SetEpsilon(args ...string) error {
+	return std.SetEpsilon(args...)
+}
+
+func (sess *Session) setEpsilon(args ...string) error {
+	t := sess.epsilon
+	num := func(str string) (float64, error) {
+		f, err := strconv.ParseFloat(str, 64)
+		if err != nil || f < 0 {
+			return 0, newErr(ErrTolerance)
+		}
+		return f, nil
+	}
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "reset":
+			t = defaultEpsilon
+		case "abs", "rel":
+			if i+1 == len(args) {
+				return newErr(ErrOptionValue, args[i])
+			}
+			i++
+			f, err := num(args[i])
+			if err != nil {
+				return err
+			}
+			if args[i-1] == "abs" {
+				t.abs = f
+			} else {
+				t.rel = f
+			}
+		default:
+			f, err := num(args[i])
+			if err != nil {
+				return newErr(ErrOption, "eps", args[i])
+			}
+			t.abs, t.rel = f, f
+		}
+	}
+	sess.epsilon = t
+	return nil
+}
+
+// close reports if a and b differ by at most abs or rel times the larger one
+func (t tolerance) close(a, b float64) bool {
+	if a == b {
+		return true
+	}
+	diff := math.Abs(a - b)
+	return diff <= t.abs || diff <= t.rel*math.Max(math.Abs(a), math.Abs(b))
+}
+
+// equal compares resolved numbers or vecs field by field, ok is false for other types
+func (t tolerance) equal(a, b Node) (eq bool, ok bool) {
+	switch a := a.(type) {
+	case NumberNode:
+		b, ok := b.(NumberNode)
+		return ok && t.close(float64(a), float64(b)), ok
+	case VecNode:
+		b, ok := b.(VecNode)
+		if !ok || len(a.fields) != len(b.fields) {
+			return false, ok
+		}
+		for i := range a.fields {
+			if !t.close(float64(a.fields[i].(NumberNode)), float64(b.fields[i].(NumberNode))) {
+				return false, true
+			}
+		}
+		return true, true
+	}
+	return false, false
+}
+
+func init() {
+	functions["approx"] = funcDef{arity: -1, call: approxFunc}
+}
+
+// approxFunc resolves approx(a; b[; abs[; rel]]), missing tolerances come from eps
+func approxFunc(s *state, args []Node) (Node, error) {
+	if len(args) < 2 || len(args) > 4 {
+		return nil, newErr(ErrApproxArgs)
+	}
+	vals := make([]Node, len(args))
+	for i, a := range args {
+		v, err := s.resolve(a)
+		if err != nil {
+			return nil, err
+		}
+		vals[i] = v
+	}
+
+	t := s.sess.epsilon
+	for i, f := range []*float64{&t.abs, &t.rel} {
+		if len(vals) <= i+2 {
+			break
+		}
+		num, ok := vals[i+2].(NumberNode)
+		if !ok || num < 0 {
+			return nil, newErr(ErrApproxTol)
+		}
+		*f = float64(num)
+	}
+
+	eq, ok := t.equal(vals[0], vals[1])
+	if !ok {
+		return nil, newErr(ErrApproxKind)
+	}
+	return BoolNode(eq), nil
+}
@@ -0,0 +1,126 @@
+package vector
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestTolerance(t *testing.T) {
+	tests := []struct {
+		tol  tolerance
+		a, b float64
+		want bool
+	}{
+		{defaultEpsilon, 0.1 + 0.2, 0.3, true},
+		{defaultEpsilon, 1, 1.001, false},
+		{tolerance{abs: 0.01}, 1, 1.005, true},
+		{tolerance{abs: 0.01}, 1000, 1000.5, false},
+		{tolerance{rel: 0.001}, 1000, 1000.5, true},
+		{tolerance{rel: 0.001}, 0, 0.0001, false},
+		{tolerance{}, 2, 2, true},
+		{tolerance{}, 2, 2.0000001, false},
+	}
+	for _, tt := range tests {
+		if got := tt.tol.close(tt.a, tt.b); got != tt.want {
+			t.Errorf("%v close(%v, %v) = %v, want %v", tt.tol, tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestApprox(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+		err  Code
+	}{
+		{"0.1 + 0.2 ~= 0.3", "true", 0},
+		{"0.1 + 0.2 == 0.3", "false", 0},
+		{"0.1 + 0.2 ≈ 0.3", "true", 0},
+		{"1 ~= 1.1", "false", 0},
+		{"[0.1+0.2 1] ~= [0.3 1]", "true", 0},
+		{"[1 2] ~= [1 2.1]", "false", 0},
+		{"[1 2] ~= [1 2 0]", "false", 0},
+		{"1 ~= [1]", "", ErrCompare},
+		{"{1; 2} ~= {1; 2}", "", ErrCompare},
+		{"true ~= true", "", ErrCompare},
+		{"approx(1; 1.05; 0.1)", "true", 0},
+		{"approx(1; 1.05; 0.01)", "false", 0},
+		{"approx(1000; 1001; 0; 0.01)", "true", 0},
+		{"approx([1 2]; [1 2.05]; 0.1)", "true", 0},
+		{"approx(1)", "", ErrApproxArgs},
+		{"approx(1; 1; -1)", "", ErrApproxTol},
+		{"approx(1; 1; [1])", "", ErrApproxTol},
+		{"approx({1}; {1})", "", ErrApproxKind},
+	}
+	for _, tt := range tests {
+		res, err := NewSession().Run(context.Background(), tt.src)
+		if tt.err != 0 {
+			if !errors.Is(err, tt.err) {
+				t.Errorf("%s = %v %v, want %v", tt.src, res, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || Format(res) != tt.want {
+			t.Errorf("%s = %v %v, want %s", tt.src, res, err, tt.want)
+		}
+	}
+}
+
+func TestEps(t *testing.T) {
+	tests := []struct {
+		cmds []string
+		want string
+		err  Code
+	}{
+		{nil, "abs 1e-09, rel 1e-09", 0},
+		{[]string{"eps 0.1"}, "abs 0.1, rel 0.1", 0},
+		{[]string{"eps abs 0.5 rel 0.01"}, "abs 0.5, rel 0.01", 0},
+		{[]string{"eps 0.1", "eps reset"}, "abs 1e-09, rel 1e-09", 0},
+		{[]string{"eps abs"}, "", ErrOptionValue},
+		{[]string{"eps foo"}, "", ErrOption},
+		{[]string{"eps rel x"}, "", ErrTolerance},
+	}
+	for _, tt := range tests {
+		sess := NewSession()
+		var err error
+		for _, cmd := range tt.cmds {
+			if _, err = sess.Run(context.Background(), cmd); err != nil {
+				break
+			}
+		}
+		if tt.err != 0 {
+			if !errors.Is(err, tt.err) {
+				t.Errorf("%q = %v, want %v", tt.cmds, err, tt.err)
+			}
+			// failed eps keeps tolerance
+			if sess.epsilon != defaultEpsilon {
+				t.Errorf("%q changed tolerance to %v", tt.cmds, sess.epsilon)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%q: %v", tt.cmds, err)
+		}
+		res, err := sess.Run(context.Background(), "eps")
+		if cmd, ok := res.(Command); err != nil || !ok || cmd.Text != tt.want {
+			t.Errorf("eps after %q = %v %v, want %s", tt.cmds, res, err, tt.want)
+		}
+	}
+
+	// eps is default of ~= and approx, explicit tolerance wins
+	sess := NewSession()
+	for _, step := range []struct{ src, want string }{
+		{"1 ~= 1.05", "false"},
+		{"eps 0.1", ""},
+		{"1 ~= 1.05", "true"},
+		{"approx(1; 1.05; 0.01; 0)", "false"},
+		{"eps reset", ""},
+		{"1 ~= 1.05", "false"},
+	} {
+		res, err := sess.Run(context.Background(), step.src)
+		if err != nil || Format(res) != step.want {
+			t.Errorf("%s = %v %v, want %q", step.src, res, err, step.want)
+		}
+	}
+}
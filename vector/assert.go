@@ -0,0 +1,35 @@
+package vector
+
+import "fmt"
+
+// AssertNode fails unless expr is true
+type AssertNode struct {
+	expr Node
+}
+
+func (n AssertNode) resolve(s *state) (Node, error) {
+	res, err := s.resolve(n.expr)
+	if err != nil {
+		return nil, err
+	}
+	if b, ok := res.(BoolNode); !ok {
+		return nil, newErr(ErrAssertion, typeName(res), Format(n.expr))
+	} else if b {
+		return nil, nil
+	}
+
+	msg := Format(n.expr)
+	// show values of compared sides
+	if op, ok := n.expr.(OperationNode); ok && isCompare(op.op.ttype) {
+		left, lerr := s.resolve(op.left)
+		right, rerr := s.resolve(op.right)
+		if lerr == nil && rerr == nil {
+			msg += fmt.Sprintf(" (%s %s %s)", Format(left), op.op.val, Format(right))
+		}
+	}
+	return nil, newErr(ErrAssert, msg)
+}
+
+func (n AssertNode) String() string {
+	return fmt.Sprintf("ASSERT(%s)", n.expr)
+}
@@ -0,0 +1,36 @@
+package vector
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestAssert(t *testing.T) {
+	tests := []struct {
+		src  string
+		err  Code
+		want string
+	}{
+		{"assert 1 + 1 == 2", 0, ""},
+		{"assert true", 0, ""},
+		{"assert [1 2] ~= [1 2.0000000001]", 0, ""},
+		{"assert 1 + 1 == 3", ErrAssert, "E801: Assertion failed: 1 + 1 == 3 (2 == 3)"},
+		{"assert false", ErrAssert, "E801: Assertion failed: false"},
+		{"assert 1 + 1", ErrAssertion, "E210: assert expects bool, got num: 1 + 1"},
+		{"assert [1 2]", ErrAssertion, "E210: assert expects bool, got vec: [1 2]"},
+		{"assert x == 1", ErrUndefined, ""},
+	}
+	for _, tt := range tests {
+		res, err := NewSession().Run(context.Background(), tt.src)
+		if tt.err == 0 {
+			if err != nil || res != nil {
+				t.Errorf("%s = %v %v, want no result", tt.src, res, err)
+			}
+			continue
+		}
+		if !errors.Is(err, tt.err) || tt.want != "" && err.Error() != tt.want {
+			t.Errorf("%s = %v, want %v %s", tt.src, err, tt.err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,328 @@
+package vector
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// astNode is serializable form of syntax tree, pos is offset of token in input
+type astNode struct {
+	Type     string     `json:"type"`
+	Op       string     `json:"op,omitempty"`
+	Name     string     `json:"name,omitempty"`
+	Value    *float64   `json:"value,omitempty"`
+	Bool     *bool      `json:"bool,omitempty"`
+	Eager    bool       `json:"eager,omitempty"`
+	Params   []string   `json:"params,omitempty"`
+	Args     []string   `json:"args,omitempty"`
+	Pos      *int       `json:"pos,omitempty"`
+	Children []*astNode `json:"children,omitempty"`
+}
+
+// toAST converts n into serializable form
+func toAST(n Node) *astNode {
+	pos := func(p int) *int {
+		return &p
+	}
+	children := func(ns ...Node) []*astNode {
+		var res []*astNode
+		for _, c := range ns {
+			res = append(res, toAST(c))
+		}
+		return res
+	}
+
+	switch n := n.(type) {
+	case NumberNode:
+		f := float64(n)
+		return &astNode{Type: "num", Value: &f}
+	case BoolNode:
+		b := bool(n)
+		return &astNode{Type: "bool", Bool: &b}
+	case VecNode:
+		return &astNode{Type: "vec", Children: children(n.fields...)}
+	case ListNode:
+		return &astNode{Type: "list", Children: children(n.items...)}
+	case UnaryNode:
+		return &astNode{Type: "unary", Op: n.op.val, Pos: pos(n.op.pos), Children: children(n.node)}
+	case OperationNode:
+		return &astNode{Type: "op", Op: n.op.val, Pos: pos(n.op.pos), Children: children(n.left, n.right)}
+	case VarNode:
+		if n.val == nil {
+			return &astNode{Type: "var", Name: n.ident.val, Pos: pos(n.ident.pos)}
+		}
+		return &astNode{Type: "assign", Name: n.ident.val, Eager: n.eager, Pos: pos(n.ident.pos), Children: children(n.val)}
+	case FuncNode:
+		return &astNode{Type: "func", Name: string(n.fun), Pos: pos(n.pos), Children: children(n.args...)}
+	case CallNode:
+		return &astNode{Type: "call", Children: children(append([]Node{n.fn}, n.args...)...)}
+	case IndexNode:
+		return &astNode{Type: "index", Children: children(n.node, n.index)}
+	case LambdaNode:
+		return &astNode{Type: "lambda", Params: n.params, Children: children(n.body)}
+	case AssertNode:
+		return &astNode{Type: "assert", Children: children(n.expr)}
+	case TraceNode:
+		return &astNode{Type: "trace", Children: children(n.expr)}
+	case AstNode:
+		return &astNode{Type: "ast", Name: n.format, Children: children(n.expr)}
+	case CmdNode:
+		a := &astNode{Type: "cmd", Name: n.kw.name}
+		for _, t := range n.args {
+			a.Args = append(a.Args, t.val)
+		}
+		return a
+	case BadNode:
+		return &astNode{Type: "bad", Pos: pos(n.span.Start)}
+	}
+	return &astNode{Type: "?", Name: n.String()}
+}
+
+// node converts serialized tree back into Node
+func (a *astNode) node() (Node, error) {
+	if a == nil {
+		return nil, newErr(ErrASTMissing)
+	}
+	kids := make([]Node, len(a.Children))
+	for i, c := range a.Children {
+		n, err := c.node()
+		if err != nil {
+			return nil, err
+		}
+		kids[i] = n
+	}
+	want := func(count int) error {
+		if len(kids) != count {
+			return newErr(ErrASTChildren, a.Type, count)
+		}
+		return nil
+	}
+	var pos int
+	if a.Pos != nil {
+		pos = *a.Pos
+	}
+
+	switch a.Type {
+	case "num":
+		if a.Value == nil {
+			return nil, newErr(ErrASTField, "num", "value")
+		}
+		return NumberNode(*a.Value), nil
+	case "bool":
+		if a.Bool == nil {
+			return nil, newErr(ErrASTField, "bool", "bool")
+		}
+		return BoolNode(*a.Bool), nil
+	case "vec":
+		return VecNode{kids}, nil
+	case "list":
+		return ListNode{kids}, nil
+	case "unary", "op":
+		op, err := opToken(a.Op, pos)
+		if err != nil {
+			return nil, err
+		}
+		if a.Type == "unary" {
+			if err := want(1); err != nil {
+				return nil, err
+			}
+			return UnaryNode{op, kids[0]}, nil
+		}
+		if err := want(2); err != nil {
+			return nil, err
+		}
+		return OperationNode{kids[0], op, kids[1]}, nil
+	case "var", "assign":
+		if !isName(a.Name) && a.Name != kwANS.name {
+			return nil, newErr(ErrASTVar, a.Name)
+		}
+		node := VarNode{ident: Token{ttype: tIDENT, val: a.Name, pos: pos}, eager: a.Eager}
+		if a.Type == "var" {
+			return node, want(0)
+		}
+		if err := want(1); err != nil {
+			return nil, err
+		}
+		node.val = kids[0]
+		return node, nil
+	case "func":
+		return FuncNode{fun: function(a.Name), args: kids, pos: pos}, nil
+	case "call":
+		if len(kids) == 0 {
+			return nil, newErr(ErrASTField, "call", "function")
+		}
+		return CallNode{fn: kids[0], args: kids[1:]}, nil
+	case "index":
+		if err := want(2); err != nil {
+			return nil, err
+		}
+		return IndexNode{kids[0], kids[1]}, nil
+	case "lambda":
+		for _, p := range a.Params {
+			if !isName(p) {
+				return nil, newErr(ErrASTParam, p)
+			}
+		}
+		if err := want(1); err != nil {
+			return nil, err
+		}
+		return LambdaNode{params: a.Params, body: kids[0]}, nil
+	case "assert", "trace", "ast":
+		if err := want(1); err != nil {
+			return nil, err
+		}
+		switch a.Type {
+		case "assert":
+			return AssertNode{kids[0]}, nil
+		case "trace":
+			return TraceNode{kids[0]}, nil
+		}
+		return AstNode{a.Name, kids[0]}, nil
+	case "cmd":
+		count, ok := cmdArgs[a.Name]
+		if !ok {
+			return nil, newErr(ErrASTCommand, a.Name)
+		}
+		if count == 0 && len(a.Args) > 0 {
+			return nil, newErr(ErrASTField, a.Name, "no args")
+		} else if count == 1 && len(a.Args) != 1 {
+			return nil, newErr(ErrASTField, a.Name, "one name in args")
+		} else if count == 1 && !isName(a.Args[0]) && a.Args[0] != kwANS.name {
+			return nil, newErr(ErrASTVar, a.Args[0])
+		}
+		var kw keyWord
+		for _, k := range keywords {
+			if k.name == a.Name {
+				kw = k
+			}
+		}
+		cmd := CmdNode{kw: kw}
+		for _, arg := range a.Args {
+			cmd.args = append(cmd.args, Token{ttype: tIDENT, val: arg})
+		}
+		return cmd, nil
+	case "bad":
+		return BadNode{Span{pos, pos}}, nil
+	}
+	return nil, newErr(ErrASTType, a.Type)
+}
+
+// cmdArgs is number of args commands take like the parser reads them, -1 is any
+var cmdArgs = map[string]int{
+	kwQUIT.name: 0, kwCLEAR.name: 0, kwHELP.name: 0, kwEXPORT.name: 0, kwHISTORY.name: 0, kwVARS.name: 0, kwRESET.name: 0,
+	kwFORMAT.name: -1, kwEPS.name: -1, kwEXPLAIN.name: -1, kwLANG.name: -1,
+	kwDEPS.name: 1, kwDEL.name: 1, kwSHOW.name: 1,
+}
+
+// opToken lexes operator op, it follows an operand so || is or
+func opToken(op string, pos int) (Token, error) {
+	tokens, err := NewLexer("0 " + op).GenerateTokens()
+	if err != nil || len(tokens) != 2 || !isOperator(tokens[1].ttype) {
+		return Token{}, newErr(ErrASTOperator, op)
+	}
+	tok := tokens[1]
+	tok.pos = pos
+	return tok, nil
+}
+
+// isOperator reports if tt can be op of UnaryNode or OperationNode
+func isOperator(tt TokenType) bool {
+	return tt >= tPLUS && tt <= tROOT || tt == tABSQ || tt >= tLT && tt <= tAPPROX
+}
+
+// JSON returns syntax tree of n as JSON
+func JSON(n Node) string {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(toAST(n)); err != nil {
+		return err.Error()
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// FromJSON builds syntax tree from output of JSON
+func FromJSON(data []byte) (Node, error) {
+	var a astNode
+	if err := json.Unmarshal(data, &a); err != nil {
+		e := newErr(ErrASTJSON, err)
+		e.Err = err
+		return nil, e
+	}
+	return a.node()
+}
+
+// DOT returns syntax tree of n as Graphviz digraph
+func DOT(n Node) string {
+	var b strings.Builder
+	b.WriteString("digraph ast {\n\tnode [shape=box];\n")
+	var id int
+	var walk func(a *astNode) int
+	walk = func(a *astNode) int {
+		me := id
+		id++
+		fmt.Fprintf(&b, "\tn%d [label=%s];\n", me, strconv.Quote(a.label()))
+		for _, c := range a.Children {
+			fmt.Fprintf(&b, "\tn%d -> n%d;\n", me, walk(c))
+		}
+		return me
+	}
+	walk(toAST(n))
+	b.WriteString("}")
+	return b.String()
+}
+
+// label describes a in one line for DOT
+func (a *astNode) label() string {
+	var str string
+	switch a.Type {
+	case "num":
+		str = strconv.FormatFloat(*a.Value, 'f', -1, 64)
+	case "bool":
+		str = strconv.FormatBool(*a.Bool)
+	case "unary", "op":
+		str = a.Op
+		if a.Op == "?" {
+			str = "|x|"
+		}
+	case "var":
+		str = a.Name
+	case "assign":
+		str = a.Name + " ="
+		if a.Eager {
+			str = a.Name + " :="
+		}
+	case "func":
+		str = a.Name + "()"
+	case "lambda":
+		str = strings.Join(a.Params, "; ") + " ->"
+	case "cmd":
+		str = strings.Join(append([]string{a.Name}, a.Args...), " ")
+	default:
+		str = a.Type
+	}
+	if a.Pos != nil {
+		str += " @" + strconv.Itoa(*a.Pos)
+	}
+	return str
+}
+
+// AstNode shows syntax tree of expr without evaluating it
+type AstNode struct {
+	format string
+	expr   Node
+}
+
+func (n AstNode) resolve(s *state) (Node, error) {
+	if n.format == "dot" {
+		return output(DOT(n.expr)), nil
+	}
+	return output(JSON(n.expr)), nil
+}
+
+func (n AstNode) String() string {
+	return fmt.Sprintf("AST:%s(%s)", n.format, n.expr)
+}
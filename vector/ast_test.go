@@ -0,0 +1,93 @@
+package vector
+
+import (
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	var nodes []Node
+	for _, src := range []string{
+		"a = 1 + 2 * b",
+		"c := |x - 1| ^ 2",
+		"assert sin(pi) ~= 0",
+		"trace {1; true}[1]",
+		"ast dot a || !b",
+		"f = (x; y) -> x - y",
+		"(x -> x)(2)",
+		"format digits 4",
+		"del a",
+	} {
+		n, err := Parse(src)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", src, err)
+		}
+		nodes = append(nodes, n)
+	}
+	r := rand.New(rand.NewSource(2))
+	for i := 0; i < 500; i++ {
+		nodes = append(nodes, randNode(t, r, 4))
+	}
+	for _, n := range nodes {
+		got, err := FromJSON([]byte(JSON(n)))
+		if err != nil {
+			t.Fatalf("FromJSON(JSON(%s)): %v", Format(n), err)
+		}
+		if !reflect.DeepEqual(toAST(got), toAST(n)) {
+			t.Fatalf("FromJSON(JSON(x)) != x for %s, got %s", Format(n), Format(got))
+		}
+	}
+}
+
+func TestFromJSONErrors(t *testing.T) {
+	tests := []struct {
+		json string
+		want Code
+	}{
+		{`{"type": "num"`, ErrASTJSON},
+		{`{"type": "matrix"}`, ErrASTType},
+		{`{"type": "num"}`, ErrASTField},
+		{`{"type": "op", "op": "+", "children": [{"type": "num", "value": 1}]}`, ErrASTChildren},
+		{`{"type": "op", "op": "$", "children": [{"type": "num", "value": 1}, {"type": "num", "value": 2}]}`, ErrASTOperator},
+		{`{"type": "cmd", "name": "launch"}`, ErrASTCommand},
+		{`{"type": "cmd", "name": "vec"}`, ErrASTCommand},
+		{`{"type": "cmd", "name": "del"}`, ErrASTField},
+		{`{"type": "cmd", "name": "show", "args": []}`, ErrASTField},
+		{`{"type": "cmd", "name": "deps", "args": ["a", "b"]}`, ErrASTField},
+		{`{"type": "cmd", "name": "vars", "args": ["a"]}`, ErrASTField},
+		{`{"type": "cmd", "name": "del", "args": ["1a"]}`, ErrASTVar},
+		{`{"type": "vec", "children": [null]}`, ErrASTMissing},
+	}
+	for _, tt := range tests {
+		if _, err := FromJSON([]byte(tt.json)); !errors.Is(err, tt.want) {
+			t.Errorf("FromJSON(%s) = %v, want %v", tt.json, err, tt.want)
+		}
+	}
+}
+
+func TestFromJSONLocalized(t *testing.T) {
+	_, err := FromJSON([]byte("{"))
+	var syntaxErr *json.SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Errorf("FromJSON error %v does not wrap *json.SyntaxError", err)
+	}
+	msg := german.localize(err).Error()
+	if strings.Contains(msg, "%!") || !strings.HasPrefix(msg, "E709: Ungültiges AST-JSON: unexpected end") {
+		t.Errorf("localized error = %q", msg)
+	}
+}
+
+func TestDOT(t *testing.T) {
+	n, err := Parse("1 + a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "digraph ast {\n\tnode [shape=box];\n\tn0 [label=\"+ @2\"];\n\tn1 [label=\"1\"];\n\tn0 -> n1;\n\tn2 [label=\"a @4\"];\n\tn0 -> n2;\n}"
+	if got := DOT(n); got != want {
+		t.Errorf("DOT = %q, want %q", got, want)
+	}
+}
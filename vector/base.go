@@ -0,0 +1,72 @@
+package vector
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// prefixes of number literals in bases other than 10
+var prefixes = map[int]string{2: "0b", 8: "0o", 16: "0x"}
+
+func init() {
+	functions["hex"] = baseFunc("hex", 16)
+	functions["bin"] = baseFunc("bin", 2)
+	functions["oct"] = baseFunc("oct", 8)
+}
+
+// parseNum reads number literal like 1.5, 1_000 or 0x1F, _ separates digits
+func parseNum(str string) (float64, error) {
+	base, digits := 10, str
+	if len(str) > 2 && str[0] == '0' {
+		for b, prefix := range prefixes {
+			if strings.EqualFold(str[:2], prefix) {
+				base, digits = b, str[2:]
+			}
+		}
+	}
+	if strings.HasPrefix(digits, "_") || strings.HasSuffix(digits, "_") ||
+		strings.Contains(digits, "__") || strings.Contains(digits, "_.") || strings.Contains(digits, "._") {
+		return 0, newErr(ErrNumber, str)
+	}
+	digits = strings.ReplaceAll(digits, "_", "")
+	if base == 10 {
+		return strconv.ParseFloat(digits, 64)
+	}
+	n, err := strconv.ParseUint(digits, base, 64)
+	return float64(n), err
+}
+
+// baseNum writes f in base with prefix like 0x1F, ok is false unless f is whole and below 2^63 in size
+func baseNum(f float64, base int) (string, bool) {
+	if f != math.Trunc(f) || math.Abs(f) >= 1<<63 {
+		return "", false
+	}
+	n, sign := int64(f), ""
+	if n < 0 {
+		n, sign = -n, "-"
+	}
+	return sign + prefixes[base] + strings.ToUpper(strconv.FormatInt(n, base)), true
+}
+
+// baseFunc shows whole number in base, result is output and no number
+func baseFunc(name string, base int) funcDef {
+	return funcDef{arity: 1, call: func(s *state, args []Node) (Node, error) {
+		n, err := s.resolve(args[0])
+		if err != nil {
+			return nil, err
+		}
+		num, ok := n.(NumberNode)
+		if !ok {
+			return nil, newErr(ErrArgNumber, name)
+		}
+		str, ok := baseNum(float64(num), base)
+		if !ok && math.Abs(float64(num)) >= 1<<63 {
+			return nil, newErr(ErrArgRange, name, Format(num))
+		}
+		if !ok {
+			return nil, newErr(ErrArgWhole, name, Format(num))
+		}
+		return output(str), nil
+	}}
+}
@@ -0,0 +1,124 @@
+package vector
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestParseNum(t *testing.T) {
+	tests := []struct {
+		str  string
+		want float64
+	}{
+		{"1.5", 1.5},
+		{"1_000", 1000},
+		{"1_000.25", 1000.25},
+		{"0x1F", 31},
+		{"0X1f", 31},
+		{"0xFF_FF", 65535},
+		{"0b101", 5},
+		{"0B1_0", 2},
+		{"0o17", 15},
+		{"007", 7},
+	}
+	for _, tt := range tests {
+		if got, err := parseNum(tt.str); err != nil || got != tt.want {
+			t.Errorf("parseNum(%q) = %v %v, want %v", tt.str, got, err, tt.want)
+		}
+	}
+	for _, str := range []string{"_1", "1_", "1__0", "1_.5", "1._5", "0x_1", "0b12", "0o8", "0xG", "0x", "1.2.3"} {
+		if _, err := parseNum(str); err == nil {
+			t.Errorf("parseNum(%q) succeeded, want error", str)
+		}
+	}
+}
+
+func TestNumberLiterals(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+		err  Code
+	}{
+		{"0x10 + 0b10 + 0o10", "26", 0},
+		{"1_000 * 2", "2000", 0},
+		{"0b12", "", ErrNumber},
+		{"1__0", "", ErrNumber},
+		{"1.5.2", "", ErrNumber},
+	}
+	for _, tt := range tests {
+		res, err := Run(tt.src)
+		if tt.err != 0 {
+			if !errors.Is(err, tt.err) {
+				t.Errorf("%s = %v %v, want %v", tt.src, res, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || Format(res) != tt.want {
+			t.Errorf("%s = %v %v, want %s", tt.src, res, err, tt.want)
+		}
+	}
+}
+
+func TestBaseFuncs(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+		err  Code
+	}{
+		{"hex(255)", "0xFF", 0},
+		{"hex(0)", "0x0", 0},
+		{"bin(-5)", "-0b101", 0},
+		{"oct(8)", "0o10", 0},
+		{"hex(0xABC)", "0xABC", 0},
+		{"hex(1.5)", "", ErrArgWhole},
+		{"hex(2^70)", "", ErrArgRange},
+		{"bin(-2^63)", "", ErrArgRange},
+		{"oct(2^62)", "0o400000000000000000000", 0},
+		{"bin([1 2])", "", ErrArgNumber},
+	}
+	for _, tt := range tests {
+		res, err := Run(tt.src)
+		if tt.err != 0 {
+			if !errors.Is(err, tt.err) {
+				t.Errorf("%s = %v %v, want %v", tt.src, res, err, tt.err)
+			}
+			continue
+		}
+		if cmd, ok := res.(Command); err != nil || !ok || cmd.Text != tt.want {
+			t.Errorf("%s = %v %v, want %s", tt.src, res, err, tt.want)
+		}
+	}
+}
+
+func TestFormatBase(t *testing.T) {
+	sess := NewSession()
+	if err := sess.SetFormat("base", "16"); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		src, want string
+	}{
+		{"255", "0xFF"},
+		{"-16", "-0x10"},
+		{"1.5", "1.5"},
+		{"[10 2.5]", "vec(0xA 2.5)"},
+	}
+	for _, tt := range tests {
+		res, err := sess.Run(context.Background(), tt.src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := sess.Show(res); got != tt.want {
+			t.Errorf("%s shows %q, want %q", tt.src, got, tt.want)
+		}
+	}
+	for _, base := range []string{"3", "10.5", "x"} {
+		if err := sess.SetFormat("base", base); err == nil {
+			t.Errorf("SetFormat(base %s) succeeded, want error", base)
+		}
+	}
+	if err := sess.SetFormat("base", "7"); !errors.Is(err, ErrBase) {
+		t.Errorf("SetFormat(base 7) = %v, want %v", err, ErrBase)
+	}
+}
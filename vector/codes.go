@@ -0,0 +1,315 @@
+package vector
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// codes of errors, hundreds are Category
+const (
+	ErrCharacter      Code = 1
+	ErrNumber         Code = 2
+	ErrExpected       Code = 3
+	ErrIncomplete     Code = 4
+	ErrChained        Code = 5
+	ErrNestedAssign   Code = 6
+	ErrVecInVec       Code = 7
+	ErrAssignInVec    Code = 8
+	ErrDuplicateParam Code = 9
+	ErrExpectedVar    Code = 10
+	ErrExpectedParen  Code = 11
+	ErrDecimalComma   Code = 12
+
+	ErrDivisionByZero Code = 101
+	ErrNegativeRoot   Code = 102
+	ErrDivideByVec    Code = 103
+	ErrVecPower       Code = 104
+
+	ErrType      Code = 201
+	ErrAdd       Code = 202
+	ErrSubtract  Code = 203
+	ErrCompare   Code = 204
+	ErrOrder     Code = 205
+	ErrBool      Code = 206
+	ErrCall      Code = 207
+	ErrIndex     Code = 208
+	ErrStore     Code = 209
+	ErrAssertion Code = 210
+	ErrNoValue   Code = 211
+	ErrNoOperand Code = 212
+
+	ErrUndefined      Code = 301
+	ErrConstant       Code = 302
+	ErrCycle          Code = 303
+	ErrVarName        Code = 304
+	ErrFuncName       Code = 305
+	ErrDeleteConstant Code = 306
+	ErrInUse          Code = 307
+	ErrUnused         Code = 308
+	ErrUnknownCode    Code = 309
+	ErrBuiltin        Code = 310
+
+	ErrArity       Code = 401
+	ErrLambdaArity Code = 402
+	ErrArgNumber   Code = 403
+	ErrArgItems    Code = 404
+	ErrArgFunc     Code = 405
+	ErrReduceArgs  Code = 406
+	ErrReduceEmpty Code = 407
+	ErrIfArgs      Code = 408
+	ErrIfNone      Code = 409
+	ErrIndexWhole  Code = 410
+	ErrIndexRange  Code = 411
+	ErrApproxArgs  Code = 412
+	ErrApproxTol   Code = 413
+	ErrApproxKind  Code = 414
+	ErrNoResult    Code = 415
+	ErrArgWhole    Code = 416
+	ErrArgRange    Code = 417
+
+	ErrDepth    Code = 501
+	ErrSteps    Code = 502
+	ErrLength   Code = 503
+	ErrCanceled Code = 504
+
+	ErrOption      Code = 601
+	ErrOptionValue Code = 602
+	ErrDigits      Code = 603
+	ErrOnOff       Code = 604
+	ErrTolerance   Code = 605
+	ErrLang        Code = 606
+	ErrBase        Code = 607
+
+	ErrASTMissing  Code = 701
+	ErrASTChildren Code = 702
+	ErrASTField    Code = 703
+	ErrASTVar      Code = 704
+	ErrASTParam    Code = 705
+	ErrASTCommand  Code = 706
+	ErrASTType     Code = 707
+	ErrASTOperator Code = 708
+	ErrASTJSON     Code = 709
+
+	ErrAssert Code = 801
+
+	ErrKeyword        Code = 901
+	ErrOperator       Code = 902
+	ErrNotImplemented Code = 903
+	ErrCompile        Code = 904
+	ErrCompileAssign  Code = 905
+)
+
+// codeInfo describes code, format makes message from args
+type codeInfo struct {
+	title   string
+	format  string
+	explain string
+}
+
+var codes = map[Code]codeInfo{
+	ErrCharacter: {"invalid character", "Invalid character: %s",
+		"The input holds a character that is no part of the language, like $ or a single & or ~."},
+	ErrNumber: {"invalid number", "%s is not a number",
+		"A number has more than one decimal point, digits not of its base like 0b12 or _ not between digits. Write 1.5, 1_000 or 0x1F."},
+	ErrExpected: {"unexpected token", "Expected %s",
+		"The parser found something else than the language allows at this place, like a missing closing parenthesis before more input."},
+	ErrIncomplete: {"incomplete input", "Expected %s",
+		"The input ends before the expression is complete. The REPL asks for another line, an empty line gives up."},
+	ErrChained: {"chained comparison", "Comparisons cannot be chained",
+		"a < b < c is not allowed because it is unclear what it means. Combine comparisons with && like a < b && b < c."},
+	ErrNestedAssign: {"assignment in assignment", "Cannot assign variable in variable assignment",
+		"The value of an assignment cannot assign another variable, like a = b = 1. Assign each variable on its own."},
+	ErrVecInVec: {"vec in vec", "Vec in vec not allowed",
+		"Fields of a vec are numbers. To group several vecs use a list like {vec(1 2); vec(3 4)}."},
+	ErrAssignInVec: {"assignment in vec", "Cannot assign var in vec",
+		"Fields of a vec are expressions, assign variables before creating the vec."},
+	ErrDuplicateParam: {"duplicate parameter", "Duplicate parameter %s",
+		"Every parameter of a lambda needs its own name, (x; x) -> x is not allowed."},
+	ErrExpectedVar: {"variable expected", "Expected variable after %s",
+		"Keywords like deps, show and del take the name of a variable, like show a."},
+	ErrExpectedParen: {"parenthesis expected", "Expected ( after %s",
+		"Functions are called with their arguments in parentheses, like sin(pi)."},
+	ErrDecimalComma: {"decimal comma", "Invalid character: ,",
+		"With lang en numbers are written with decimal point like 1.5. With lang de both 1,5 and 1.5 are read."},
+
+	ErrDivisionByZero: {"division by zero", "Division by zero",
+		"A number or a field of a vec was divided by 0, which has no result."},
+	ErrNegativeRoot: {"negative root", "Negative number in root",
+		"Roots of negative numbers are not real numbers, vector only calculates with real numbers."},
+	ErrDivideByVec: {"division by vec", "Cannot divide by Vec: %s",
+		"Vecs can be divided by numbers but nothing can be divided by a vec."},
+	ErrVecPower: {"power of vec", "Pow for vec not implemented: %s",
+		"Powers and roots only work with numbers, use * for products of vecs."},
+
+	ErrType: {"unexpected type", "Unexpected type: %s",
+		"The operator does not work with the types of its operands, vars shows the type of every variable."},
+	ErrAdd: {"cannot add", "Cannot add %s and %s: %s",
+		"Only numbers can be added to numbers and vecs to vecs, the shorter vec is padded with zeros."},
+	ErrSubtract: {"cannot subtract", "Cannot subtract %s and %s: %s",
+		"Only numbers can be subtracted from numbers and vecs from vecs, the shorter vec is padded with zeros."},
+	ErrCompare: {"cannot compare", "Cannot compare %s and %s: %s",
+		"== and != compare values of the same type, ~= compares numbers or vecs."},
+	ErrOrder: {"cannot order", "Cannot order %s and %s: %s",
+		"<, >, <= and >= only compare numbers."},
+	ErrBool: {"bool expected", "Expected bool, got %s: %s",
+		"!, && and || take bools, which are made by comparisons or true and false."},
+	ErrCall: {"not callable", "Cannot call %s: %s",
+		"Only functions and lambdas like x -> x * 2 can be called."},
+	ErrIndex: {"not indexable", "Cannot index %s: %s",
+		"Only lists and vecs have items which can be read by index like v[1]."},
+	ErrStore: {"cannot store", "Cannot store %s in %s",
+		"Vecs hold numbers, lists hold numbers, bools, vecs and lists."},
+	ErrAssertion: {"assert type", "assert expects bool, got %s: %s",
+		"assert checks a condition like a == 3, other values cannot be true or false."},
+	ErrNoValue: {"no value", "No value for %s",
+		"The input has no value which can be used in Go, like the output of a keyword."},
+	ErrNoOperand: {"missing operand", "Operand of %s has no value",
+		"The operand of the operator has no value, like an assignment which returns nothing."},
+
+	ErrUndefined: {"not defined", "%s is not defined",
+		"The variable or function was never assigned or was deleted. vars lists all variables."},
+	ErrConstant: {"constant", "%s is a constant",
+		"Constants like pi and e cannot be assigned, choose another name. Sessions and scripts of versions before constants which assign e or pi fail with this error."},
+	ErrCycle: {"cycle", "Cycle %s",
+		"Formulas which read themselves, maybe through other variables, never end. := stores the current value instead of the formula."},
+	ErrVarName: {"invalid variable name", "%s is not a valid variable name",
+		"Names start with a letter and hold letters, digits and _, they cannot be keywords."},
+	ErrFuncName: {"invalid function name", "%s is not a valid function name",
+		"Names start with a letter and hold letters, digits and _, they cannot be keywords."},
+	ErrDeleteConstant: {"delete constant", "Cannot delete constant %s",
+		"Constants like pi and e always exist."},
+	ErrInUse: {"variable in use", "Cannot delete %s, used by %s",
+		"Other formulas read the variable, delete or change them first. deps shows who uses a variable."},
+	ErrUnused: {"unused input", "%s is not used by program",
+		"The compiled program does not read this variable, so setting it has no effect."},
+	ErrUnknownCode: {"unknown code", "Unknown error code %s",
+		"Codes are E and three digits like E101, explain without code lists all codes."},
+	ErrBuiltin: {"built-in function", "%s is a built-in function",
+		"Functions registered from Go cannot replace functions like sin or map, choose another name."},
+
+	ErrArity: {"wrong argument count", "%s expects %s",
+		"The function was called with too many or too few arguments, help lists all functions."},
+	ErrLambdaArity: {"wrong argument count", "Function %s expects %s",
+		"A lambda takes exactly one argument for each of its parameters."},
+	ErrArgNumber: {"number expected", "%s expects number",
+		"The function calculates with a number, like sin(pi)."},
+	ErrArgItems: {"list expected", "%s expects list or vec",
+		"The function works on the items of a list or the fields of a vec."},
+	ErrArgFunc: {"function expected", "%s expects function like x -> x * 2",
+		"The function applies a lambda or function to every item."},
+	ErrReduceArgs: {"reduce arguments", "reduce expects list, optional start and function",
+		"reduce({1; 2; 3}; (a; b) -> a + b) combines items from left to right, reduce(list; start; f) starts with start."},
+	ErrReduceEmpty: {"reduce of empty list", "reduce of empty list needs start value",
+		"An empty list has no first item to start with, pass a start value like reduce(list; 0; f)."},
+	ErrIfArgs: {"if arguments", "if expects condition and value",
+		"if takes pairs of condition and value, optionally followed by a value when no condition is true."},
+	ErrIfNone: {"no true condition", "No condition of if is true",
+		"No condition of if is true and there is no else value as last argument."},
+	ErrIndexWhole: {"index not whole", "Index must be whole number: %s",
+		"Items are counted 1, 2, 3, ..., there is no item 1.5."},
+	ErrIndexRange: {"index out of range", "Index %s out of range 1..%d",
+		"Items are counted from 1 up to len(list)."},
+	ErrApproxArgs: {"approx arguments", "approx expects 2 to 4 arguments",
+		"approx(a; b) compares with eps, approx(a; b; abs) and approx(a; b; abs; rel) use own tolerances."},
+	ErrApproxTol: {"approx tolerance", "approx expects tolerance number >= 0",
+		"Tolerances are numbers which are 0 or more."},
+	ErrApproxKind: {"approx values", "approx expects two numbers or two vecs",
+		"approx compares numbers with numbers and vecs with vecs of the same length."},
+	ErrNoResult: {"no result", "%s returned no value",
+		"A function registered from Go returned nil without error."},
+	ErrArgWhole: {"whole number expected", "%s expects whole number: %s",
+		"Only whole numbers are shown in other bases, like hex(255)."},
+	ErrArgRange: {"number too large", "%s expects number of size below 2^63: %s",
+		"Other bases only show numbers of 63 bits, like hex(2^62)."},
+
+	ErrDepth: {"too deep", "Maximum depth of %d exceeded",
+		"The evaluation nests too deep, often because of a lambda which calls itself without end."},
+	ErrSteps: {"too many steps", "Maximum of %d steps exceeded",
+		"The evaluation takes too long and was stopped."},
+	ErrLength: {"vec too long", "Maximum vec length of %d exceeded",
+		"The vec grew longer than allowed."},
+	ErrCanceled: {"canceled", "Evaluation canceled: %v",
+		"The evaluation was stopped from outside, like by the time limit of the server."},
+
+	ErrOption: {"unknown option", "Unknown %s option: %s",
+		"The keyword does not know this option, help lists all options."},
+	ErrOptionValue: {"option value missing", "Expected value after %s",
+		"The option needs a value, like format digits 4 or eps abs 0.01."},
+	ErrDigits: {"invalid digits", "Digits must be between 0 and 17",
+		"Numbers are shown with at most 17 digits, more digits do not exist in a float64."},
+	ErrOnOff: {"on or off expected", "Expected on or off",
+		"Switches like frac and group are turned on or off, like format frac on."},
+	ErrTolerance: {"invalid tolerance", "Tolerance must be number >= 0",
+		"Tolerances of ~= are numbers which are 0 or more."},
+	ErrLang: {"unknown language", "Unknown language %s, choose one of %s",
+		"Messages and numbers are shown in en or de, like lang de."},
+	ErrBase: {"invalid base", "Base must be 2, 8, 10 or 16",
+		"Whole numbers are shown binary, octal, decimal or hexadecimal, like format base 16."},
+
+	ErrASTMissing: {"AST node missing", "Missing node in AST",
+		"A child of a node in the AST JSON is null."},
+	ErrASTChildren: {"AST children", "AST node %s expects %d children",
+		"The node has a wrong number of children, ast json 'expression' shows valid trees."},
+	ErrASTField: {"AST field missing", "AST node %s needs %s",
+		"The node lacks a field which is needed to build it."},
+	ErrASTVar: {"AST variable name", "Invalid variable name in AST: %s",
+		"Names start with a letter and hold letters, digits and _."},
+	ErrASTParam: {"AST parameter name", "Invalid parameter name in AST: %s",
+		"Names start with a letter and hold letters, digits and _."},
+	ErrASTCommand: {"AST command", "Unknown command in AST: %s",
+		"cmd nodes name keywords like vars or export."},
+	ErrASTType: {"AST node type", "Unknown AST node type: %s",
+		"Types are num, bool, vec, list, unary, op, var, assign, func, call, index, lambda, assert, trace, ast and cmd."},
+	ErrASTOperator: {"AST operator", "Invalid operator in AST: %s",
+		"op holds an operator of the language like + or <=."},
+	ErrASTJSON: {"AST JSON", "Invalid AST JSON: %s",
+		"The AST is no valid JSON."},
+
+	ErrAssert: {"assertion failed", "Assertion failed: %s",
+		"The asserted condition is false, both sides of a comparison are shown in parentheses."},
+
+	ErrKeyword: {"keyword not implemented", "Keyword not implemented",
+		"The keyword is reserved but does nothing yet."},
+	ErrOperator: {"operator not implemented", "Unary operator not implemented: %s",
+		"The operator cannot be put in front of a value."},
+	ErrNotImplemented: {"not implemented", "Not implemented: %s",
+		"The operation is not implemented for these values."},
+	ErrCompile: {"cannot compile", "Cannot compile: %s",
+		"Compiled programs only hold numbers, variables, operators and functions of one number."},
+	ErrCompileAssign: {"cannot compile assignment", "Cannot compile assignment: %s",
+		"Compiled programs cannot assign variables, set inputs from Go instead."},
+}
+
+// hints help to fix errors of some codes
+var hints = map[Code]string{
+	ErrChained:      "use && like a < b && b < c",
+	ErrVecInVec:     "group vecs in a list like {vec(1 2); vec(3 4)}",
+	ErrDecimalComma: "write 1.5, or switch to decimal comma with lang de",
+	ErrCycle:        "use := to assign current value",
+	ErrUnknownCode:  "codes look like E101, explain lists all codes",
+	ErrReduceEmpty:  "pass start value like reduce(list; 0; f)",
+	ErrConstant:     "pi and e are constants, rename such variables like e1 = ...",
+}
+
+// explain describes code for explain keyword, without code it lists all codes
+func explain(lang *locale, code string) (string, error) {
+	if code == "" {
+		var all []int
+		for c := range codes {
+			all = append(all, int(c))
+		}
+		sort.Ints(all)
+		var lines []string
+		for _, c := range all {
+			lines = append(lines, fmt.Sprintf("%s  %-9s  %s", Code(c).String(), lang.category(Code(c)), lang.codes[Code(c)].title))
+		}
+		return strings.Join(lines, "\n"), nil
+	}
+	c, ok := ParseCode(code)
+	if !ok {
+		return "", newErr(ErrUnknownCode, code)
+	}
+	info := lang.codes[c]
+	return fmt.Sprintf("%s %s (%s)\n%s", c.String(), info.title, lang.category(c), info.explain), nil
+}
@@ -0,0 +1,273 @@
+package vector
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// CommandKind tells frontend what to do with Command
+type CommandKind int
+
+const (
+	// CmdOutput shows Text
+	CmdOutput CommandKind = iota
+	// CmdHelp shows help in Text
+	CmdHelp
+	// CmdClear clears screen
+	CmdClear
+	// CmdExit ends session
+	CmdExit
+)
+
+var cmdKinds = [...]string{"output", "help", "clear", "exit"}
+
+func (k CommandKind) String() string {
+	return cmdKinds[k]
+}
+
+// Command is result of keyword asking frontend for action instead of value,
+// frontends decide how to show Text, clear screen or exit
+type Command struct {
+	Kind CommandKind
+	Text string
+}
+
+func (c Command) resolve(s *state) (Node, error) {
+	return c, nil
+}
+
+func (c Command) String() string {
+	return c.Text
+}
+
+// output returns command showing text
+func output(text string) Command {
+	return Command{Kind: CmdOutput, Text: text}
+}
+
+// CmdNode is keyword command evaluated for its output
+type CmdNode struct {
+	kw   keyWord
+	args []Token
+}
+
+func (n CmdNode) resolve(s *state) (Node, error) {
+	switch n.kw.name {
+	case kwQUIT.name:
+		return Command{Kind: CmdExit}, nil
+	case kwCLEAR.name:
+		return Command{Kind: CmdClear}, nil
+	case kwHELP.name:
+		return Command{Kind: CmdHelp, Text: s.sess.lang.help}, nil
+	case kwEXPORT.name:
+		return output(s.sess.export()), nil
+	case kwHISTORY.name:
+		var lines []string
+		for i, h := range s.sess.history {
+			lines = append(lines, fmt.Sprintf("%3d  %s", i+1, h))
+		}
+		return output(strings.Join(lines, "\n")), nil
+	case kwFORMAT.name:
+		if len(n.args) == 0 {
+			return output(s.sess.display.String()), nil
+		}
+		var args []string
+		for _, a := range n.args {
+			args = append(args, a.val)
+		}
+		return nil, s.sess.setFormat(args...)
+	case kwEPS.name:
+		if len(n.args) == 0 {
+			return output(s.sess.epsilon.String()), nil
+		}
+		var args []string
+		for _, a := range n.args {
+			args = append(args, a.val)
+		}
+		return nil, s.sess.setEpsilon(args...)
+	case kwLANG.name:
+		switch len(n.args) {
+		case 0:
+			return output(s.sess.lang.name), nil
+		case 1:
+			return nil, s.sess.setLang(n.args[0].val)
+		}
+		return nil, newErr(ErrOption, kwLANG.name, n.args[1].val)
+	case kwEXPLAIN.name:
+		var code string
+		if len(n.args) > 0 {
+			code = n.args[0].val
+		}
+		info, err := explain(s.sess.lang, code)
+		if err != nil {
+			return nil, err
+		}
+		return output(info), nil
+	case kwDEPS.name:
+		info, err := s.sess.depsInfo(n.args[0].val)
+		if err != nil {
+			return nil, err
+		}
+		return output(info), nil
+	case kwVARS.name:
+		return output(listVars(s)), nil
+	case kwSHOW.name:
+		info, err := showVar(s, n.args[0].val)
+		if err != nil {
+			return nil, err
+		}
+		return output(info), nil
+	case kwDEL.name:
+		return nil, s.sess.remove(n.args[0].val)
+	case kwRESET.name:
+		s.sess.reset()
+		s.sess.history = nil
+		return nil, nil
+	}
+	return nil, newErr(ErrKeyword)
+}
+
+func (n CmdNode) String() string {
+	return fmt.Sprintf("%s%v", strings.ToUpper(n.kw.name), n.args)
+}
+
+// typeName returns name of value type
+func typeName(n Node) string {
+	switch n.(type) {
+	case NumberNode:
+		return "num"
+	case VecNode:
+		return "vec"
+	case BoolNode:
+		return "bool"
+	case ListNode:
+		return "list"
+	case LambdaNode:
+		return "func"
+	case Command:
+		return "command"
+	}
+	return "?"
+}
+
+// varNames returns sorted names of memory with ans last
+func (sess *Session) varNames() []string {
+	var names []string
+	for name := range sess.memory {
+		if name != kwANS.name {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if _, ok := sess.memory[kwANS.name]; ok {
+		names = append(names, kwANS.name)
+	}
+	return names
+}
+
+// listVars returns table of variables and constants
+func listVars(s *state) string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tEXPRESSION\tVALUE\tTYPE")
+	for _, v := range vars(s) {
+		if v.Const {
+			v.Type += " const"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", v.Name, v.Expression, v.Value, v.Type)
+	}
+	w.Flush()
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// showVar describes variable name
+func showVar(s *state, name string) (string, error) {
+	if c, ok := constants[name]; ok {
+		return fmt.Sprintf("%s = %s\ntype: %s const", name, c, typeName(c)), nil
+	}
+	if _, ok := s.sess.memory[name]; !ok {
+		return "", newErr(ErrUndefined, name)
+	}
+	val, err := s.resolve(VarNode{ident: Token{ttype: tIDENT, val: name}})
+	if err != nil {
+		return "", err
+	}
+	lines := []string{
+		Format(VarNode{ident: Token{ttype: tIDENT, val: name}, val: s.sess.memory[name]}),
+		"value: " + s.sess.display.show(val),
+		"type:  " + typeName(val),
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// remove deletes variable name unless formulas read it
+func (sess *Session) remove(name string) error {
+	if _, ok := constants[name]; ok {
+		return newErr(ErrDeleteConstant, name)
+	}
+	if _, ok := sess.memory[name]; !ok {
+		return newErr(ErrUndefined, name)
+	}
+	if users := sess.graph.dependents(name, false); len(users) > 0 {
+		return newErr(ErrInUse, name, strings.Join(users, ", "))
+	}
+	sess.invalidate(name)
+	delete(sess.memory, name)
+	delete(sess.graph, name)
+	return nil
+}
+
+// export returns memory as assignments in input syntax
+func (sess *Session) export() string {
+	var lines []string
+	for _, name := range sess.varNames() {
+		if name == kwANS.name {
+			continue
+		}
+		lines = append(lines, Format(VarNode{ident: Token{ttype: tIDENT, val: name}, val: sess.memory[name]}))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// helpText is shown by help keyword
+const helpText = `HELP
+Assign variable:    $ 'name' = 'expression'
+Assign value:       $ 'name' := 'expression'
+Show dependencies:  $ deps 'name'
+Variables:          $ vars | $ show 'name' | $ del 'name' | $ reset
+Constants:          pi | e  (read only, scripts assigning e = ... or pi = ... need another name)
+End program:        $ quit | $ close | $ end | $ exit
+Create vector:      $ vec('x' 'y' 'z' ...) | ['x' 'y' 'z' ...] | vec('x';'y';'z';...) | ['x';'y';'z';...]
+Create list:        $ {'a'; 'b'; ...}
+Index:              $ 'list'['i'] | 'vec'['i']  (counting from 1)
+Lambda:             $ x -> 'expression' | (a; b) -> 'expression'  (f = ... reads other variables on each use, f := ... keeps their values)
+Call function:      $ 'name'('a'; 'b') | (x -> 'expression')('a')
+Export variables:   $ export | $ save
+Show history:       $ history
+Number format:      $ format [fix | sci | eng] [digits 'n'] [frac on | off] [group on | off] [base 2 | 8 | 10 | 16] | $ format reset
+Numbers:            1_000_000 | 0x1F | 0b1010 | 0o17
+Assert:             $ assert 'condition'  (check files with: vec test 'file' ...)
+Syntax tree:        $ ast [json | dot] 'expression'  (or start with --dump-ast=json|dot)
+Trace evaluation:   $ trace 'expression'  (or start with --trace)
+Tolerance:          $ eps [abs 'x'] [rel 'x'] | $ eps 'x' | $ eps reset
+Explain error:      $ explain 'code' | $ explain  (errors start with codes like E101)
+Language:           $ lang [en | de] | $ lang reset  (de shows 1,5 and reads 1,5 and 1.5)
+
+Operator:
+	Add:        '+'
+	Subtract:   '-'
+	Multiply:   '*'
+	Devide:     '/' | ':'
+	Power:      '^'
+	Root:       '\'
+	Compare:    '<' | '>' | '<=' | '>=' | '==' | '!=' | '~=' | '≈'
+	Logic:      '&&' | '||' | '!'  with true | false
+
+Functions:
+	sin('x') | cos('x') | tan('x') | log('x') | ln('x')
+	len('list') | sum('list') | map('list'; 'lambda') | filter('list'; 'lambda') | reduce('list'; ['start';] 'lambda')
+	if('cond'; 'a'; ['cond2'; 'b'; ...] 'else')  (only taken branch is evaluated)
+	approx('a'; 'b'; ['abs'; ['rel']])  (tolerances default to eps)
+	hex('x') | bin('x') | oct('x')  (shows whole number in base 16, 2 or 8)`
@@ -0,0 +1,142 @@
+package vector
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// session returns session which ran srcs
+func session(t *testing.T, srcs ...string) *Session {
+	t.Helper()
+	sess := NewSession()
+	for _, src := range srcs {
+		if _, err := sess.Run(context.Background(), src); err != nil {
+			t.Fatalf("%s: %v", src, err)
+		}
+	}
+	return sess
+}
+
+// cmdText runs src in sess and returns text of its Command
+func cmdText(t *testing.T, sess *Session, src string) (string, error) {
+	t.Helper()
+	res, err := sess.Run(context.Background(), src)
+	if err != nil {
+		return "", err
+	}
+	cmd, ok := res.(Command)
+	if !ok {
+		t.Fatalf("%s = %v, want Command", src, res)
+	}
+	return cmd.Text, nil
+}
+
+func TestVars(t *testing.T) {
+	sess := session(t, "a = 1", "b = a * 2", "b + 1")
+	want := []Var{
+		{Name: "a", Expression: "1", Value: "1", Type: "num"},
+		{Name: "b", Expression: "a * 2", Value: "2", Type: "num"},
+		{Name: "ans", Expression: "3", Value: "3", Type: "num"},
+		{Name: "e", Value: "2.71828182845905", Type: "num", Const: true},
+		{Name: "pi", Value: "3.14159265358979", Type: "num", Const: true},
+	}
+	if got := sess.Vars(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Vars() = %+v, want %+v", got, want)
+	}
+
+	text, err := cmdText(t, sess, "vars")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(text, "\n")
+	if len(lines) != 6 || strings.Fields(lines[0])[0] != "NAME" || !reflect.DeepEqual(strings.Fields(lines[2]), []string{"b", "a", "*", "2", "2", "num"}) {
+		t.Errorf("vars = %q", text)
+	}
+	if !strings.HasSuffix(lines[5], "num const") {
+		t.Errorf("vars does not mark constants: %q", lines[5])
+	}
+}
+
+func TestVarsFailing(t *testing.T) {
+	sess := session(t, "d = 1", "c = 1 / d", "d = 0")
+	vars := sess.Vars()
+	if vars[0].Name != "c" || !strings.Contains(vars[0].Value, "E101") || vars[0].Type != "" {
+		t.Errorf("failing variable = %+v, want error as value", vars[0])
+	}
+}
+
+func TestShow(t *testing.T) {
+	sess := session(t, "a = 1", "b = a * 2")
+	tests := []struct {
+		src  string
+		want string
+		err  Code
+	}{
+		{"show b", "b = a * 2\nvalue: 2\ntype:  num", 0},
+		{"show pi", "pi = 3.14159265358979\ntype: num const", 0},
+		{"show zz", "", ErrUndefined},
+		{"show 1", "", ErrExpectedVar},
+	}
+	for _, tt := range tests {
+		got, err := cmdText(t, sess, tt.src)
+		if tt.err != 0 {
+			if !errors.Is(err, tt.err) {
+				t.Errorf("%s = %v, want %v", tt.src, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("%s = %q %v, want %q", tt.src, got, err, tt.want)
+		}
+	}
+}
+
+func TestDel(t *testing.T) {
+	sess := session(t, "a = 1", "b = a * 2", "c = 3")
+	tests := []struct {
+		src string
+		err Code
+	}{
+		{"del a", ErrInUse},
+		{"del zz", ErrUndefined},
+		{"del pi", ErrDeleteConstant},
+		{"del c", 0},
+		{"del b", 0},
+		{"del a", 0},
+	}
+	for _, tt := range tests {
+		_, err := sess.Run(context.Background(), tt.src)
+		if tt.err == 0 && err != nil || tt.err != 0 && !errors.Is(err, tt.err) {
+			t.Errorf("%s = %v, want %v", tt.src, err, tt.err)
+		}
+	}
+	if _, err := sess.Run(context.Background(), "a"); !errors.Is(err, ErrUndefined) {
+		t.Errorf("a after del = %v, want %v", err, ErrUndefined)
+	}
+}
+
+func TestReset(t *testing.T) {
+	sess := session(t, "a = 1", "b = a + 1", "format digits 3", "reset")
+	if len(sess.memory) != 0 || len(sess.graph) != 0 {
+		t.Errorf("reset kept %v", sess.memory)
+	}
+	if h := sess.History(); len(h) != 0 {
+		t.Errorf("history after reset = %q, want empty", h)
+	}
+	if _, err := sess.Run(context.Background(), "b"); !errors.Is(err, ErrUndefined) {
+		t.Errorf("b after reset = %v, want %v", err, ErrUndefined)
+	}
+	// settings stay, format reset restores them
+	if sess.display.digits != 3 {
+		t.Errorf("reset changed format to %v", sess.display)
+	}
+	if _, err := sess.Run(context.Background(), "c = 2"); err != nil {
+		t.Fatal(err)
+	}
+	if h := sess.History(); !reflect.DeepEqual(h, []string{"c = 2"}) {
+		t.Errorf("history = %q, want [c = 2]", h)
+	}
+}
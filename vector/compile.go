@@ -0,0 +1,295 @@
+package vector
+
+import (
+	"context"
+	"math"
+)
+
+type opcode byte
+
+const (
+	opCONST opcode = iota
+	opLOAD
+	opUNARY
+	opBINARY
+	opVEC
+	opCALL
+)
+
+// instr is single vm instruction, arg indexes consts, regs or counts fields
+type instr struct {
+	op  opcode
+	arg int
+	tok Token
+}
+
+// value is vm value, node is nil for numbers so they stay unboxed
+type value struct {
+	num  float64
+	node Node
+}
+
+func (v value) toNode() Node {
+	if v.node == nil {
+		return NumberNode(v.num)
+	}
+	return v.node
+}
+
+func toValue(n Node) value {
+	if num, ok := n.(NumberNode); ok {
+		return value{num: float64(num)}
+	}
+	return value{node: n}
+}
+
+// Program is compiled expression run by a stack vm, runs lock the session but
+// Program itself is not safe for concurrent use
+type Program struct {
+	code   []instr
+	consts []value
+	names  []string
+	regs   []value
+	set    []bool
+	stack  []value
+	state  *state
+}
+
+// Compile compiles src into Program for repeated evaluation
+func Compile(src string) (*Program, error) {
+	ast, err := Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	std.mu.Lock()
+	defer std.mu.Unlock()
+	p := &Program{state: newState(context.Background(), std)}
+	depth, err := p.compile(ast)
+	if err != nil {
+		return nil, err
+	}
+	p.stack = make([]value, depth)
+	return p, nil
+}
+
+// compile emits code for n and returns stack depth it needs
+func (p *Program) compile(n Node) (int, error) {
+	switch n := n.(type) {
+	case NumberNode:
+		p.consts = append(p.consts, value{num: float64(n)})
+		p.code = append(p.code, instr{op: opCONST, arg: len(p.consts) - 1})
+		return 1, nil
+	case VarNode:
+		if n.val != nil {
+			return 0, newErr(ErrCompileAssign, Format(n))
+		}
+		p.code = append(p.code, instr{op: opLOAD, arg: p.register(n.ident.val)})
+		return 1, nil
+	case UnaryNode:
+		depth, err := p.compile(n.node)
+		if err != nil {
+			return 0, err
+		}
+		if n.op.ttype != tPLUS {
+			p.code = append(p.code, instr{op: opUNARY, tok: n.op})
+		}
+		return depth, nil
+	case OperationNode:
+		if n.op.ttype == tAND || n.op.ttype == tOR {
+			return 0, newErr(ErrCompile, Format(n))
+		}
+		left, err := p.compile(n.left)
+		if err != nil {
+			return 0, err
+		}
+		right, err := p.compile(n.right)
+		if err != nil {
+			return 0, err
+		}
+		p.code = append(p.code, instr{op: opBINARY, tok: n.op})
+		if right+1 > left {
+			return right + 1, nil
+		}
+		return left, nil
+	case VecNode:
+		depth, err := p.compileList(n.fields)
+		p.code = append(p.code, instr{op: opVEC, arg: len(n.fields)})
+		return depth, err
+	case FuncNode:
+		if def, _ := lookupFunc(n.fun); def.lazy {
+			return 0, newErr(ErrCompile, Format(n))
+		}
+		depth, err := p.compileList(n.args)
+		p.code = append(p.code, instr{op: opCALL, arg: len(n.args), tok: Token{ttype: tFUNC, val: string(n.fun)}})
+		return depth, err
+	}
+	return 0, newErr(ErrCompile, Format(n))
+}
+
+// compileList emits nodes which stay on stack side by side
+func (p *Program) compileList(nodes []Node) (int, error) {
+	depth := 1
+	for i, n := range nodes {
+		d, err := p.compile(n)
+		if err != nil {
+			return 0, err
+		}
+		if d+i > depth {
+			depth = d + i
+		}
+	}
+	return depth, nil
+}
+
+// register returns register of name, stored value becomes its initial content
+func (p *Program) register(name string) int {
+	for i, n := range p.names {
+		if n == name {
+			return i
+		}
+	}
+	var val value
+	v, err := p.state.resolve(VarNode{ident: Token{ttype: tIDENT, val: name}})
+	if err == nil {
+		val = toValue(v)
+	}
+	p.names = append(p.names, name)
+	p.regs = append(p.regs, val)
+	p.set = append(p.set, err == nil)
+	return len(p.names) - 1
+}
+
+// Vars returns variables read by program
+func (p *Program) Vars() []string {
+	return append([]string(nil), p.names...)
+}
+
+// Set overrides value of variable name for following runs
+func (p *Program) Set(name string, val Node) error {
+	p.state.sess.mu.Lock()
+	defer p.state.sess.mu.Unlock()
+	for i, n := range p.names {
+		if n == name {
+			v, err := p.state.resolve(val)
+			if err != nil {
+				return err
+			}
+			p.regs[i] = toValue(v)
+			p.set[i] = true
+			return nil
+		}
+	}
+	return newErr(ErrUnused, name)
+}
+
+// Run executes program
+func (p *Program) Run() (Node, error) {
+	return p.RunContext(context.Background())
+}
+
+// RunContext executes program honoring cancellation of ctx
+func (p *Program) RunContext(ctx context.Context) (Node, error) {
+	p.state.sess.mu.Lock()
+	defer p.state.sess.mu.Unlock()
+	p.state.ctx, p.state.steps = ctx, 0
+	if err := ctx.Err(); err != nil {
+		return nil, canceled(err)
+	}
+	stack := p.stack
+	sp := 0
+	for _, in := range p.code {
+		switch in.op {
+		case opCONST:
+			stack[sp] = p.consts[in.arg]
+			sp++
+		case opLOAD:
+			if !p.set[in.arg] {
+				return nil, newErr(ErrUndefined, p.names[in.arg])
+			}
+			stack[sp] = p.regs[in.arg]
+			sp++
+		case opUNARY:
+			v := &stack[sp-1]
+			if v.node == nil {
+				switch in.tok.ttype {
+				case tMINUS:
+					v.num = -v.num
+					continue
+				case tABSQ:
+					v.num = math.Abs(v.num)
+					continue
+				}
+			}
+			res, err := p.state.resolve(UnaryNode{in.tok, v.toNode()})
+			if err != nil {
+				return nil, err
+			}
+			*v = toValue(res)
+		case opBINARY:
+			sp--
+			l, r := &stack[sp-1], stack[sp]
+			if l.node == nil && r.node == nil {
+				if num, ok, err := binaryNum(in.tok.ttype, l.num, r.num); ok {
+					if err != nil {
+						return nil, err
+					}
+					l.num = num
+					continue
+				}
+			}
+			res, err := p.state.resolve(OperationNode{l.toNode(), in.tok, r.toNode()})
+			if err != nil {
+				return nil, err
+			}
+			*l = toValue(res)
+		case opVEC:
+			vec := VecNode{fields: make([]Node, in.arg)}
+			for i := range vec.fields {
+				vec.fields[i] = stack[sp-in.arg+i].toNode()
+			}
+			sp -= in.arg
+			stack[sp] = value{node: vec}
+			sp++
+		case opCALL:
+			fun := function(in.tok.val)
+			def, _ := lookupFunc(fun)
+			if def.num != nil && in.arg == 1 && sp > 0 && stack[sp-1].node == nil {
+				stack[sp-1].num = def.num(stack[sp-1].num)
+				continue
+			}
+			call := FuncNode{fun: fun, args: make([]Node, in.arg)}
+			for i := range call.args {
+				call.args[i] = stack[sp-in.arg+i].toNode()
+			}
+			res, err := p.state.resolve(call)
+			if err != nil {
+				return nil, err
+			}
+			sp -= in.arg
+			stack[sp] = toValue(res)
+			sp++
+		}
+	}
+	return stack[0].toNode(), nil
+}
+
+// binaryNum applies number operator without boxing, ok is false if not handled
+func binaryNum(op TokenType, l, r float64) (float64, bool, error) {
+	switch op {
+	case tPLUS:
+		return l + r, true, nil
+	case tMINUS:
+		return l - r, true, nil
+	case tMUL:
+		return l * r, true, nil
+	case tDIV:
+		res, err := NumberNode(l).div(NumberNode(r))
+		return float64(res), true, err
+	case tPOW:
+		return math.Pow(l, r), true, nil
+	case tROOT:
+		res, err := NumberNode(r).rot(NumberNode(l))
+		return float64(res), true, err
+	}
+	return 0, false, nil
+}
@@ -0,0 +1,163 @@
+package vector
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// mustRun runs src in std
+func mustRun(t testing.TB, src string) Node {
+	res, err := Run(src)
+	if err != nil {
+		t.Fatalf("Run(%q): %v", src, err)
+	}
+	return res
+}
+
+func TestCompile(t *testing.T) {
+	mustRun(t, "reset")
+	for _, src := range []string{"a = 3", "v = [1 2 3]", "w := v * a"} {
+		mustRun(t, src)
+	}
+	tests := []string{
+		"1 + 2 * 3",
+		"2 ^ 10 - 1",
+		"3 \\ 27",
+		"-a + |a - 10|",
+		"?-a",
+		"sin(a) + cos(a) * tan(1)",
+		"ln(a) - log(100)",
+		"v * 2",
+		"v + [1 1 1]",
+		"a * v - w",
+		"[a a^2 a*3]",
+		"|v|",
+		"a < 4",
+		"v == w",
+		"!(a > 2)",
+	}
+	for _, src := range tests {
+		want := mustRun(t, src)
+		p, err := Compile(src)
+		if err != nil {
+			t.Fatalf("Compile(%q): %v", src, err)
+		}
+		got, err := p.Run()
+		if err != nil {
+			t.Fatalf("Program.Run(%q): %v", src, err)
+		}
+		if Format(got) != Format(want) {
+			t.Errorf("Program.Run(%q) = %s, Run = %s", src, Format(got), Format(want))
+		}
+	}
+}
+
+func TestCompileSet(t *testing.T) {
+	mustRun(t, "reset")
+	p, err := Compile("x * 2 + y")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Run(); !errors.Is(err, ErrUndefined) {
+		t.Errorf("Run with unset x = %v, want %v", err, ErrUndefined)
+	}
+	tests := []struct {
+		x, y Node
+	}{
+		{NumberNode(5), NumberNode(1)},
+		{NumberNode(-2), NumberNode(0.5)},
+		{VecNode{fields: []Node{NumberNode(1), NumberNode(2)}}, VecNode{fields: []Node{NumberNode(3), NumberNode(4)}}},
+	}
+	for _, tt := range tests {
+		if err := p.Set("x", tt.x); err != nil {
+			t.Fatal(err)
+		}
+		if err := p.Set("y", tt.y); err != nil {
+			t.Fatal(err)
+		}
+		got, err := p.Run()
+		if err != nil {
+			t.Fatal(err)
+		}
+		mustRun(t, "x = "+Format(tt.x))
+		mustRun(t, "y = "+Format(tt.y))
+		if want := mustRun(t, "x * 2 + y"); Format(got) != Format(want) {
+			t.Errorf("x = %s, y = %s: Program.Run = %s, Run = %s", Format(tt.x), Format(tt.y), Format(got), Format(want))
+		}
+	}
+	if err := p.Set("z", NumberNode(1)); !errors.Is(err, ErrUnused) {
+		t.Errorf("Set of unused var = %v, want %v", err, ErrUnused)
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, src := range []string{"a = 1", "x -> x", "{1; 2}", "a && b", "if(1; 2; 3)"} {
+		if _, err := Compile(src); err == nil {
+			t.Errorf("Compile(%q) succeeded, want error", src)
+		}
+	}
+}
+
+const benchExpr = "sin(x) * 2 + x ^ 2 - |x - 3| / (1 + cos(x))"
+
+func BenchmarkCompiled(b *testing.B) {
+	mustRun(b, "x = 1.5")
+	p, err := Compile(benchExpr)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := p.Run(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkTreeWalk(b *testing.B) {
+	mustRun(b, "x = 1.5")
+	ast, err := Parse(benchExpr)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := newState(context.Background(), std).run(ast); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestCompileNoArgs(t *testing.T) {
+	if err := RegisterFunc("answer", 0, func(args ...Value) (Value, error) {
+		return Number(42), nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		src  string
+		want string
+		err  Code
+	}{
+		{"sin()", "", ErrArity},
+		{"answer()", "42", 0},
+		{"answer() + sin(0)", "42", 0},
+	}
+	for _, tt := range tests {
+		p, err := Compile(tt.src)
+		if err != nil {
+			t.Fatalf("Compile(%q): %v", tt.src, err)
+		}
+		got, err := p.Run()
+		if tt.err != 0 {
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Program.Run(%q) = %v, want %v", tt.src, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || Format(got) != tt.want {
+			t.Errorf("Program.Run(%q) = %v %v, want %s", tt.src, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package vector
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	nFIX = "fix"
+	nSCI = "sci"
+	nENG = "eng"
+)
+
+// display holds number output settings
+type display struct {
+	digits   int
+	notation string
+	fraction bool
+	group    bool
+	// base shows whole numbers binary, octal or hexadecimal
+	base int
+	// lang gives separators of numbers, format reset keeps it
+	lang *locale
+}
+
+var defaultDisplay = display{digits: 15, notation: nFIX, base: 10, lang: english}
+
+func (d display) String() string {
+	onOff := map[bool]string{true: "on", false: "off"}
+	return fmt.Sprintf("digits %d, notation %s, frac %s, group %s, base %d",
+		d.digits, d.notation, onOff[d.fraction], onOff[d.group], d.base)
+}
+
+// SetFormat changes number output like the format keyword, e.g. SetFormat("digits", "4")
+func SetFormat(args ...string) error {
+	return std.SetFormat(args...)
+}
+
+func (sess *Session) setFormat(args ...string) error {
+	d := sess.display
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case nFIX, nSCI, nENG:
+			d.notation = args[i]
+		case "reset":
+			d = defaultDisplay
+			d.lang = sess.display.lang
+		case "digits", "frac", "group", "base":
+			if i+1 == len(args) {
+				return newErr(ErrOptionValue, args[i])
+			}
+			i++
+			if args[i-1] == "base" {
+				n, err := strconv.Atoi(args[i])
+				if _, ok := prefixes[n]; err != nil || !ok && n != 10 {
+					return newErr(ErrBase)
+				}
+				d.base = n
+				continue
+			}
+			if args[i-1] == "digits" {
+				n, err := strconv.Atoi(args[i])
+				if err != nil || n < 0 || n > 17 {
+					return newErr(ErrDigits)
+				}
+				d.digits = n
+				continue
+			}
+			if args[i] != "on" && args[i] != "off" {
+				return newErr(ErrOnOff)
+			}
+			if args[i-1] == "frac" {
+				d.fraction = args[i] == "on"
+			} else {
+				d.group = args[i] == "on"
+			}
+		default:
+			return newErr(ErrOption, "format", args[i])
+		}
+	}
+	sess.display = d
+	return nil
+}
+
+// show returns value n with numbers in format of d
+func (d display) show(n Node) string {
+	var parts []string
+	switch n := n.(type) {
+	case nil:
+		return ""
+	case NumberNode:
+		return d.num(float64(n))
+	case VecNode:
+		for _, f := range n.fields {
+			parts = append(parts, d.show(f))
+		}
+		return "vec(" + strings.Join(parts, " ") + ")"
+	case ListNode:
+		for _, it := range n.items {
+			parts = append(parts, d.show(it))
+		}
+		return "{" + strings.Join(parts, "; ") + "}"
+	}
+	return n.String()
+}
+
+// num formats f with settings of d
+func (d display) num(f float64) string {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	if d.base != 10 {
+		if str, ok := baseNum(f, d.base); ok {
+			return str
+		}
+	}
+	if d.fraction {
+		if p, q, ok := fraction(f); ok && q != 1 {
+			return strconv.FormatInt(p, 10) + "/" + strconv.FormatInt(q, 10)
+		}
+	}
+	if d.digits > 0 {
+		f, _ = strconv.ParseFloat(strconv.FormatFloat(f, 'g', d.digits, 64), 64)
+	}
+	if f == 0 {
+		return "0"
+	}
+
+	notation := d.notation
+	// fixed notation of very large or small numbers is a long run of digits
+	if abs := math.Abs(f); notation == nFIX && (abs >= 1e21 || abs < 1e-6) {
+		notation = nSCI
+	}
+	switch notation {
+	case nSCI, nENG:
+		// exponent and mantissa come from exact decimal form, dividing by powers of 10 is off
+		prec := -1
+		if d.digits > 0 {
+			prec = d.digits - 1
+		}
+		str := strconv.FormatFloat(f, 'e', prec, 64)
+		i := strings.IndexByte(str, 'e')
+		exp, _ := strconv.Atoi(str[i+1:])
+		shift := 0
+		if notation == nENG {
+			shift = exp - int(math.Floor(float64(exp)/3))*3
+		}
+		mant, _ := strconv.ParseFloat(str[:i]+"e"+strconv.Itoa(shift), 64)
+		return d.lang.number(strconv.FormatFloat(mant, 'f', -1, 64)) + "e" + strconv.Itoa(exp-shift)
+	}
+
+	str := strconv.FormatFloat(f, 'f', -1, 64)
+	if d.group {
+		str = group(str)
+	}
+	return d.lang.number(str)
+}
+
+// group inserts thousands separators into integer part of str
+func group(str string) string {
+	var sign string
+	if strings.HasPrefix(str, "-") {
+		sign, str = "-", str[1:]
+	}
+	frac := ""
+	if i := strings.IndexByte(str, '.'); i >= 0 {
+		str, frac = str[:i], str[i:]
+	}
+	for i := len(str) - 3; i > 0; i -= 3 {
+		str = str[:i] + "," + str[i:]
+	}
+	return sign + str + frac
+}
+
+// fraction approximates f by p/q using continued fractions
+func fraction(f float64) (int64, int64, bool) {
+	const maxDen = 10000
+	if math.Abs(f) > 1e12 {
+		return 0, 0, false
+	}
+	var p0, q0, p1, q1 int64 = 0, 1, 1, 0
+	x := f
+	for i := 0; i < 32; i++ {
+		a := int64(math.Floor(x))
+		p0, p1 = p1, a*p1+p0
+		q0, q1 = q1, a*q1+q0
+		if q1 > maxDen {
+			return 0, 0, false
+		}
+		if math.Abs(float64(p1)/float64(q1)-f) <= 1e-12*math.Max(1, math.Abs(f)) {
+			return p1, q1, true
+		}
+		x = 1 / (x - float64(a))
+		if math.IsInf(x, 0) {
+			break
+		}
+	}
+	return 0, 0, false
+}
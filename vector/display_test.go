@@ -0,0 +1,100 @@
+package vector
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestDisplay(t *testing.T) {
+	tests := []struct {
+		format []string
+		num    float64
+		want   string
+	}{
+		{nil, 1.0 / 3, "0.333333333333333"},
+		{[]string{"digits", "4"}, math.Pi, "3.142"},
+		{[]string{"digits", "0"}, 1.0 / 3, "0.3333333333333333"},
+		{[]string{"sci"}, 12345, "1.2345e4"},
+		{[]string{"eng"}, 12345, "12.345e3"},
+		{[]string{"sci", "digits", "2"}, 0.000123, "1.2e-4"},
+		{[]string{"frac", "on"}, 0.75, "3/4"},
+		{[]string{"frac", "on"}, math.Pi, "3.14159265358979"},
+		{[]string{"group", "on"}, -1234567.5, "-1,234,567.5"},
+		{[]string{"group", "on", "reset"}, 1234567, "1234567"},
+		{nil, math.Inf(-1), "-Inf"},
+		{nil, 1e300, "1e300"},
+		{nil, math.Pow(2, 70), "1.18059162071741e21"},
+		{nil, -math.Pow(2, -70), "-8.470329472543e-22"},
+		{nil, 1e20, "100000000000000000000"},
+		{nil, 0.000001, "0.000001"},
+		{nil, 0.0000001234, "1.234e-7"},
+		{[]string{"digits", "3"}, 1e21, "1e21"},
+		{[]string{"eng"}, 1e-9, "1e-9"},
+		{[]string{"eng"}, -0.00012, "-120e-6"},
+		{[]string{"sci", "digits", "3"}, 9.996, "1e1"},
+	}
+	for _, tt := range tests {
+		sess := NewSession()
+		if err := sess.SetFormat(tt.format...); err != nil {
+			t.Fatalf("SetFormat(%q): %v", tt.format, err)
+		}
+		if got := sess.Show(NumberNode(tt.num)); got != tt.want {
+			t.Errorf("format %q of %v = %q, want %q", tt.format, tt.num, got, tt.want)
+		}
+	}
+}
+
+func TestDisplayVec(t *testing.T) {
+	sess := NewSession()
+	sess.SetFormat("digits", "3")
+	vec := VecNode{fields: []Node{NumberNode(math.Pi), NumberNode(math.E)}}
+	if got, want := sess.Show(vec), "vec(3.14 2.72)"; got != want {
+		t.Errorf("Show = %q, want %q", got, want)
+	}
+	if got, want := sess.display.String(), "digits 3, notation fix, frac off, group off, base 10"; got != want {
+		t.Errorf("String = %q, want %q", got, want)
+	}
+}
+
+func TestShowNil(t *testing.T) {
+	sess := NewSession()
+	for _, src := range []string{"a = 3", "f = x -> x * 2", "format base 16"} {
+		res, err := sess.Run(context.Background(), src)
+		if err != nil {
+			t.Fatalf("%s: %v", src, err)
+		}
+		if res == nil {
+			if got := sess.Show(res); got != "" {
+				t.Errorf("Show of nil result of %s = %q, want empty", src, got)
+			}
+		}
+	}
+	if got := Show(nil); got != "" {
+		t.Errorf("Show(nil) = %q, want empty", got)
+	}
+}
+
+func TestSetFormatErrors(t *testing.T) {
+	tests := []struct {
+		format []string
+		want   Code
+	}{
+		{[]string{"digits", "18"}, ErrDigits},
+		{[]string{"digits", "x"}, ErrDigits},
+		{[]string{"digits"}, ErrOptionValue},
+		{[]string{"frac", "maybe"}, ErrOnOff},
+		{[]string{"bold"}, ErrOption},
+	}
+	for _, tt := range tests {
+		sess := NewSession()
+		err := sess.SetFormat(tt.format...)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("SetFormat(%q) = %v, want %v", tt.format, err, tt.want)
+		}
+		if sess.display != defaultDisplay {
+			t.Errorf("SetFormat(%q) changed display on error", tt.format)
+		}
+	}
+}
@@ -1,94 +1,184 @@
 package vector
 
 import (
-	"log"
-	"os"
-	"os/exec"
-	"runtime"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 )
 
-// CharacterErr on invalid character
-type CharacterErr struct{ msg string }
+// Code identifies kind of error, codes stay the same across versions.
+// Code is error itself, so errors.Is(err, ErrDivisionByZero) finds errors of that kind
+type Code int
+
+// Category groups codes, it is code divided by 100
+type Category int
+
+// categories of codes
+const (
+	CatSyntax Category = iota
+	CatMath
+	CatType
+	CatName
+	CatArgument
+	CatLimit
+	CatSetting
+	CatAST
+	CatAssert
+	CatImplement
+)
+
+var catNames = [...]string{"syntax", "math", "type", "name", "argument", "limit", "setting", "ast", "assert", "implement"}
+
+func (c Category) String() string {
+	if c < 0 || int(c) >= len(catNames) {
+		return "unknown"
+	}
+	return catNames[c]
+}
+
+// Category returns group of c
+func (c Code) Category() Category {
+	return Category(c / 100)
+}
 
-func (e CharacterErr) Error() string {
-	return "Invalid character: " + e.msg
+// String returns c like E101
+func (c Code) String() string {
+	return fmt.Sprintf("E%03d", int(c))
 }
 
-// SyntaxErr is syntax err
-type SyntaxErr struct{ msg string }
+func (c Code) Error() string {
+	return c.String() + " " + codes[c].title
+}
 
-func (e SyntaxErr) Error() string {
-	// return "Invalid Syntax: " + e.msg
-	return e.msg
+// ParseCode reads code like E101
+func ParseCode(str string) (Code, bool) {
+	if len(str) != 4 || (str[0] != 'E' && str[0] != 'e') {
+		return 0, false
+	}
+	n, err := strconv.Atoi(str[1:])
+	if err != nil {
+		return 0, false
+	}
+	_, ok := codes[Code(n)]
+	return Code(n), ok
 }
 
-// RuntimeErr from nodes
-type RuntimeErr struct{ msg string }
+// Span is part of input as byte offsets, End is 0 when position is unknown
+type Span struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
 
-func (e RuntimeErr) Error() string {
-	return e.msg
+// Known reports if span points into input
+func (s Span) Known() bool {
+	return s.End > 0
 }
 
-// ImplementErr occurens when not implemented
-type ImplementErr struct{ msg string }
+// Error is error of vector, Code tells its kind
+type Error struct {
+	Code Code
+	Msg  string
+	Span Span
+	Hint string
+	// Err is cause like context.DeadlineExceeded
+	Err error
+	// args of message, kept to translate it
+	args []interface{}
+}
 
-func (e ImplementErr) Error() string {
-	return e.msg
+// newErr formats message of code with args
+func newErr(code Code, args ...interface{}) Error {
+	return Error{Code: code, Msg: fmt.Sprintf(codes[code].format, args...), Hint: hints[code], args: args}
 }
 
-// ExitErr is exit err
-type ExitErr struct{}
+// at returns e placed at start..end of input
+func (e Error) at(start, end int) Error {
+	e.Span = Span{start, end}
+	return e
+}
 
-func (e ExitErr) Error() string {
-	return "exit"
+func (e Error) Error() string {
+	return e.Code.String() + ": " + e.Msg
 }
 
-// ClearErr clears screen
-type ClearErr struct{}
+// Category returns group of e
+func (e Error) Category() Category {
+	return e.Code.Category()
+}
+
+// Is reports if target is code of e
+func (e Error) Is(target error) bool {
+	c, ok := target.(Code)
+	return ok && c == e.Code
+}
+
+// canceled wraps error of done context
+func canceled(err error) Error {
+	e := newErr(ErrCanceled, err)
+	e.Err = err
+	return e
+}
+
+// Unwrap returns cause
+func (e Error) Unwrap() error {
+	return e.Err
+}
+
+// ErrorList holds errors found in one pass like all syntax errors of input
+type ErrorList []Error
+
+func (l ErrorList) Error() string {
+	var msgs []string
+	for _, e := range l {
+		msgs = append(msgs, e.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Is reports if any error of l has code target
+func (l ErrorList) Is(target error) bool {
+	for _, e := range l {
+		if e.Is(target) {
+			return true
+		}
+	}
+	return false
+}
 
-// Clear clears screen
-func (e ClearErr) Clear() {
-	switch runtime.GOOS {
-	case "windows":
-		log.Println("windows")
-		cmd := exec.Command("cmd", "/c", "cls")
-		cmd.Stdout = os.Stdout
-		cmd.Run()
-	case "linux":
-		cmd := exec.Command("clear")
-		cmd.Stdout = os.Stdout
-		cmd.Run()
+// Unwrap returns errors of l, so errors.As finds Error in list
+func (l ErrorList) Unwrap() []error {
+	errs := make([]error, len(l))
+	for i, e := range l {
+		errs[i] = e
 	}
+	return errs
 }
 
-func (e ClearErr) Error() string {
-	switch runtime.GOOS {
-	case "js":
-		return "clear"
-	default:
-		return ""
+// err returns nil for empty l and single error without list
+func (l ErrorList) err() error {
+	switch len(l) {
+	case 0:
+		return nil
+	case 1:
+		return l[0]
 	}
+	return l
 }
 
-// HelpErr is Help err
-type HelpErr struct{}
-
-func (e HelpErr) Error() string {
-	switch runtime.GOOS {
-	case "js":
-		return "help"
-	default:
-		return `HELP
-Assign variable:    $ 'name' = 'expression'
-End program:        $ quit | $ close | $ end | $ exit
-Create vector:      $ vec('x' 'y' 'z' ...) | ['x' 'y' 'z' ...] | vec('x';'y';'z';...) | ['x';'y';'z';...]
-
-Operator:
-	Add:        '+'
-	Subtract:   '-'
-	Multiply:   '*'
-	Devide:     '/' | ':'
-	Power:      '^'
-	Root:       '\'`
+// joinErrs merges errors of passes over same input in order of their position
+func joinErrs(errs ...error) error {
+	var l ErrorList
+	for _, err := range errs {
+		switch err := err.(type) {
+		case Error:
+			l = append(l, err)
+		case ErrorList:
+			l = append(l, err...)
+		}
 	}
+	sort.SliceStable(l, func(i, j int) bool {
+		return l[i].Span.Known() && (!l[j].Span.Known() || l[i].Span.Start < l[j].Span.Start)
+	})
+	return l.err()
 }
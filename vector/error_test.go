@@ -0,0 +1,62 @@
+package vector
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		src   string
+		codes []Code
+		spans []Span
+	}{
+		{"1 + 2", nil, nil},
+		{"1 + * 2 + (3 * ) + $", []Code{ErrExpected, ErrExpected, ErrCharacter}, []Span{{4, 5}, {15, 16}, {19, 20}}},
+		{"1 < 2 < 3 + )", []Code{ErrChained, ErrExpected}, []Span{{6, 7}, {12, 13}}},
+		{"1 ) * 3 + [1 *]", []Code{ErrExpected, ErrExpected}, []Span{{2, 3}, {13, 14}}},
+		{"f(1; * 2) - (4 /)", []Code{ErrExpected, ErrExpected}, []Span{{5, 6}, {16, 17}}},
+		{"1 + * 2 +", []Code{ErrExpected, ErrIncomplete}, []Span{{4, 5}, {}}},
+		{"* 1", []Code{ErrExpected}, []Span{{0, 1}}},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.src)
+		var list ErrorList
+		switch err := err.(type) {
+		case Error:
+			list = ErrorList{err}
+		case ErrorList:
+			list = err
+		}
+		if len(list) != len(tt.codes) {
+			t.Errorf("Parse(%q) = %v, want %d errors", tt.src, err, len(tt.codes))
+			continue
+		}
+		for i, e := range list {
+			if e.Code != tt.codes[i] || e.Span != tt.spans[i] {
+				t.Errorf("Parse(%q) error %d = %v at %v, want %v at %v", tt.src, i, e, e.Span, tt.codes[i], tt.spans[i])
+			}
+		}
+	}
+}
+
+func TestErrorList(t *testing.T) {
+	_, err := Parse("1 + * 2 + (3 * ) + $")
+	list, ok := err.(ErrorList)
+	if !ok {
+		t.Fatalf("Parse = %v, want ErrorList", err)
+	}
+	var e Error
+	if !errors.As(err, &e) || e.Code != ErrExpected || e.Span != list[0].Span {
+		t.Errorf("errors.As = %v, want first error of list", e)
+	}
+	if !errors.Is(err, ErrCharacter) || errors.Is(err, ErrDivisionByZero) {
+		t.Errorf("errors.Is does not match codes of list %v", err)
+	}
+	if got := len(list.Unwrap()); got != len(list) {
+		t.Errorf("Unwrap returns %d errors, want %d", got, len(list))
+	}
+	if err := (ErrorList{}).err(); err != nil {
+		t.Errorf("empty list err() = %v, want nil", err)
+	}
+}
@@ -0,0 +1,137 @@
+package vector
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Format returns node in input syntax so that Parse(Format(n)) yields equal tree
+func Format(n Node) string {
+	return format(n, false)
+}
+
+// format writes n, compact omits spaces which separate fields inside vec
+func format(n Node, compact bool) string {
+	sp := " "
+	if compact {
+		sp = ""
+	}
+
+	switch n := n.(type) {
+	case nil:
+		return ""
+	case NumberNode:
+		return strconv.FormatFloat(float64(n), 'f', -1, 64)
+	case BoolNode:
+		return n.String()
+	case VecNode:
+		var fields []string
+		for _, f := range n.fields {
+			// fields parse a sum
+			if prec(f) < precSUM {
+				fields = append(fields, "("+format(f, true)+")")
+				continue
+			}
+			fields = append(fields, format(f, true))
+		}
+		// lexer separates by spaces only in outermost vec
+		sep := " "
+		if compact {
+			sep = ";"
+		}
+		return "[" + strings.Join(fields, sep) + "]"
+	case UnaryNode:
+		if n.op.ttype == tABSQ {
+			// bars parse a sum, adjacent bars would read as ||
+			inner := format(n.node, compact)
+			if prec(n.node) < precSUM || strings.HasPrefix(inner, "|") || strings.HasSuffix(inner, "|") {
+				inner = "(" + inner + ")"
+			}
+			return "|" + inner + "|"
+		} else if n.op.ttype == tNOT && prec(n.node) >= precNOT {
+			return n.op.val + format(n.node, compact)
+		}
+		if prec(n.node) < precATOM {
+			return n.op.val + "(" + format(n.node, compact) + ")"
+		}
+		return n.op.val + format(n.node, compact)
+	case OperationNode:
+		p := prec(n)
+		left, right := format(n.left, compact), format(n.right, compact)
+		if prec(n.left) < p || p == precCOMPARE && prec(n.left) == p {
+			left = "(" + left + ")"
+		}
+		if prec(n.right) <= p {
+			right = "(" + right + ")"
+		}
+		op := n.op.val
+		if n.op.ttype == tOR {
+			// keeps || apart from bars of abs like |a| || b
+			sp = " "
+		}
+		if n.op.ttype == tDIV {
+			op = "/"
+		} else if n.op.ttype == tAPPROX {
+			op = "~="
+		}
+		return left + sp + op + sp + right
+	case VarNode:
+		if n.val == nil {
+			return n.ident.val
+		}
+		op := "="
+		if n.eager {
+			op = ":="
+		}
+		return n.ident.val + sp + op + sp + format(n.val, compact)
+	case FuncNode:
+		var args []string
+		for _, a := range n.args {
+			args = append(args, format(a, compact))
+		}
+		return string(n.fun) + "(" + strings.Join(args, ";"+sp) + ")"
+	case ListNode:
+		var items []string
+		for _, it := range n.items {
+			items = append(items, format(it, compact))
+		}
+		return "{" + strings.Join(items, ";"+sp) + "}"
+	case IndexNode:
+		node := format(n.node, compact)
+		if prec(n.node) < precATOM {
+			node = "(" + node + ")"
+		}
+		return node + "[" + format(n.index, true) + "]"
+	case CallNode:
+		fn := format(n.fn, compact)
+		if prec(n.fn) < precATOM {
+			fn = "(" + fn + ")"
+		}
+		var args []string
+		for _, a := range n.args {
+			args = append(args, format(a, compact))
+		}
+		return fn + "(" + strings.Join(args, ";"+sp) + ")"
+	case LambdaNode:
+		params := strings.Join(n.params, ";"+sp)
+		if len(n.params) != 1 {
+			params = "(" + params + ")"
+		}
+		return params + sp + "->" + sp + format(n.body, compact)
+	case AssertNode:
+		return kwASSERT.name + " " + format(n.expr, compact)
+	case TraceNode:
+		return kwTRACE.name + " " + format(n.expr, compact)
+	case AstNode:
+		return kwAST.name + " " + n.format + " " + format(n.expr, compact)
+	case Command:
+		return n.Kind.String()
+	case CmdNode:
+		str := n.kw.name
+		for _, a := range n.args {
+			str += " " + a.val
+		}
+		return str
+	}
+	return n.String()
+}
@@ -0,0 +1,121 @@
+package vector
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// opTok lexes operator between operands
+func opTok(t *testing.T, op string) Token {
+	tokens, err := NewLexer("a " + op + " a").GenerateTokens()
+	if err != nil || len(tokens) != 3 {
+		t.Fatalf("lexing %q: %v %v", op, tokens, err)
+	}
+	tok := tokens[1]
+	tok.pos = 0
+	return tok
+}
+
+// randNode returns random valid tree of at most depth levels
+func randNode(t *testing.T, r *rand.Rand, depth int) Node {
+	ident := func() Token {
+		return Token{ttype: tIDENT, val: []string{"a", "b", "x"}[r.Intn(3)]}
+	}
+	if depth == 0 || r.Intn(4) == 0 {
+		switch r.Intn(3) {
+		case 0:
+			return NumberNode(r.Intn(100))
+		case 1:
+			return NumberNode(float64(r.Intn(1000)) / 8)
+		}
+		return VarNode{ident: ident()}
+	}
+	sub := func() Node {
+		return randNode(t, r, depth-1)
+	}
+	switch r.Intn(10) {
+	case 0:
+		return UnaryNode{op: opTok(t, "-"), node: sub()}
+	case 1:
+		return UnaryNode{op: Token{ttype: tABSQ, val: "?"}, node: sub()}
+	case 2:
+		return UnaryNode{op: opTok(t, "!"), node: sub()}
+	case 3:
+		return FuncNode{fun: "sin", args: []Node{sub()}}
+	case 4:
+		var vec VecNode
+		for i := r.Intn(3) + 1; i > 0; i-- {
+			f := sub()
+			for {
+				if _, ok := f.(VecNode); !ok {
+					break
+				}
+				f = sub()
+			}
+			vec.fields = append(vec.fields, f)
+		}
+		return vec
+	case 5:
+		return ListNode{items: []Node{sub(), sub()}}
+	case 6:
+		return LambdaNode{params: []string{"x"}, body: sub()}
+	case 7:
+		return IndexNode{node: sub(), index: sub()}
+	}
+	ops := []string{"+", "-", "*", "/", "^", "\\", "<", "==", "&&", "||"}
+	return OperationNode{left: sub(), op: opTok(t, ops[r.Intn(len(ops))]), right: sub()}
+}
+
+// shape returns tree of n without positions
+func shape(n Node) *astNode {
+	var strip func(a *astNode) *astNode
+	strip = func(a *astNode) *astNode {
+		a.Pos = nil
+		for _, c := range a.Children {
+			strip(c)
+		}
+		return a
+	}
+	return strip(toAST(n))
+}
+
+func TestFormatRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 5000; i++ {
+		n := randNode(t, r, 4)
+		src := Format(n)
+		parsed, err := Parse(src)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", src, err)
+		}
+		if !reflect.DeepEqual(shape(parsed), shape(n)) {
+			t.Fatalf("Parse(Format(x)) != x for %q, got %q", src, Format(parsed))
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"| |[3 4]| - 1|", "|(|[3 4]| - 1)|"},
+		{"|(a < b)|", "|(a < b)|"},
+		{"?-2", "|-2|"},
+		{"[(a < b) 2]", "[(a<b) 2]"},
+		{"[[1 2]*2 3]", "[[1;2]*2 3]"},
+		{"|a| || b", "|a| || b"},
+		{"x -> x ^ 2", "x -> x ^ 2"},
+		{"w = v[1 + 1] * 2", "w = v[1+1] * 2"},
+		{"{1; 2}[ [1 2][1] ]", "{1; 2}[[1;2][1]]"},
+	}
+	for _, tt := range tests {
+		n, err := Parse(tt.in)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.in, err)
+		}
+		if got := Format(n); got != tt.want {
+			t.Errorf("Format(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -1,20 +1,79 @@
 package vector
 
+import (
+	"math"
+	"sync"
+)
+
 type function string
 
-var functions = []function{
-	"sin",
-	"cos",
-	"tan",
-	"log",
-	"ln",
+// funcDef describes callable function, arity -1 accepts any count,
+// lazy functions decide themselves which args to resolve
+type funcDef struct {
+	arity int
+	lazy  bool
+	num   func(float64) float64
+	call  func(s *state, args []Node) (Node, error)
+}
+
+var functions = map[function]funcDef{
+	"sin": numFunc(math.Sin),
+	"cos": numFunc(math.Cos),
+	"tan": numFunc(math.Tan),
+	"log": numFunc(math.Log10),
+	"ln":  numFunc(math.Log),
+}
+
+// numFunc wraps math function of one number
+func numFunc(fn func(float64) float64) funcDef {
+	return funcDef{arity: 1, num: fn}
+}
+
+// funcMu guards functions and funcGen, hosts may register functions while sessions run
+var funcMu sync.RWMutex
+
+// hostFuncs are functions registered from Go, only they may be registered again
+var hostFuncs = map[function]bool{}
+
+// lookupFunc returns definition of name
+func lookupFunc(name function) (funcDef, bool) {
+	funcMu.RLock()
+	defer funcMu.RUnlock()
+	def, ok := functions[name]
+	return def, ok
 }
 
 func isFunc(str string) bool {
-	for _, fn := range functions {
-		if string(fn) == str {
-			return true
-		}
+	_, ok := lookupFunc(function(str))
+	return ok
+}
+
+// apply calls function with unresolved args
+func (d funcDef) apply(s *state, name function, args []Node) (Node, error) {
+	if d.arity >= 0 && len(args) != d.arity {
+		return nil, newErr(ErrArity, string(name), arguments(d.arity))
+	}
+	if d.num == nil {
+		return d.call(s, args)
+	}
+	arg, err := s.resolve(args[0])
+	if err != nil {
+		return nil, err
+	}
+	num, ok := arg.(NumberNode)
+	if !ok {
+		return nil, newErr(ErrArgNumber, string(name))
 	}
-	return false
+	return NumberNode(d.num(float64(num))), nil
 }
+
+// arguments is count of arguments in messages, locale translates it
+type arguments int
+
+func (n arguments) String() string {
+	return english.count(int(n))
+}
+
+// word is placeholder in messages like expression, locale translates it.
+// Names and input stay string, so they are never translated
+type word string
@@ -0,0 +1,172 @@
+package vector
+
+import (
+	"sort"
+	"strings"
+)
+
+// depGraph maps variable to variables its stored formula reads
+type depGraph map[string][]string
+
+// store sets variable and invalidates values depending on it
+func (sess *Session) store(name string, val Node, deps []string) {
+	sess.memory[name] = val
+	if len(deps) == 0 {
+		delete(sess.graph, name)
+	} else {
+		sess.graph[name] = deps
+	}
+	sess.invalidate(name)
+}
+
+// invalidate drops cached values of name and everything depending on it
+func (sess *Session) invalidate(name string) {
+	delete(sess.cache, name)
+	for _, d := range sess.graph.dependents(name, true) {
+		delete(sess.cache, d)
+	}
+}
+
+// cycle returns path from name back to itself if name read deps
+func (g depGraph) cycle(name string, deps []string) []string {
+	seen := map[string]bool{}
+	var walk func(path []string, deps []string) []string
+	walk = func(path []string, deps []string) []string {
+		for _, d := range deps {
+			if d == name {
+				return append(path, d)
+			}
+			if seen[d] {
+				continue
+			}
+			seen[d] = true
+			if p := walk(append(path, d), g[d]); p != nil {
+				return p
+			}
+		}
+		return nil
+	}
+	return walk([]string{name}, deps)
+}
+
+// dependencies returns variables name reads, all includes indirect ones
+func (g depGraph) dependencies(name string, all bool) []string {
+	return g.collect(name, all, func(n string) []string { return g[n] })
+}
+
+// dependents returns variables reading name, all includes indirect ones
+func (g depGraph) dependents(name string, all bool) []string {
+	return g.collect(name, all, func(n string) []string {
+		var res []string
+		for v, deps := range g {
+			for _, d := range deps {
+				if d == n {
+					res = append(res, v)
+					break
+				}
+			}
+		}
+		return res
+	})
+}
+
+func (g depGraph) collect(name string, all bool, next func(string) []string) []string {
+	seen := map[string]bool{name: true}
+	var res []string
+	todo := next(name)
+	for len(todo) > 0 {
+		n := todo[0]
+		todo = todo[1:]
+		if seen[n] {
+			continue
+		}
+		seen[n] = true
+		res = append(res, n)
+		if all {
+			todo = append(todo, next(n)...)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+// refs returns variables read by n, lambda params are not variables
+func refs(n Node) []string {
+	seen := map[string]bool{}
+	var res []string
+	var walk func(n Node, bound map[string]bool)
+	walk = func(n Node, bound map[string]bool) {
+		switch n := n.(type) {
+		case VarNode:
+			if n.val != nil {
+				walk(n.val, bound)
+			} else if !seen[n.ident.val] && !bound[n.ident.val] {
+				seen[n.ident.val] = true
+				res = append(res, n.ident.val)
+			}
+		case UnaryNode:
+			walk(n.node, bound)
+		case OperationNode:
+			walk(n.left, bound)
+			walk(n.right, bound)
+		case FuncNode:
+			for _, a := range n.args {
+				walk(a, bound)
+			}
+		case VecNode:
+			for _, f := range n.fields {
+				walk(f, bound)
+			}
+		case ListNode:
+			for _, it := range n.items {
+				walk(it, bound)
+			}
+		case IndexNode:
+			walk(n.node, bound)
+			walk(n.index, bound)
+		case AssertNode:
+			walk(n.expr, bound)
+		case TraceNode:
+			walk(n.expr, bound)
+		case CallNode:
+			walk(n.fn, bound)
+			for _, a := range n.args {
+				walk(a, bound)
+			}
+		case LambdaNode:
+			inner := map[string]bool{}
+			for name := range bound {
+				inner[name] = true
+			}
+			for _, p := range n.params {
+				inner[p] = true
+			}
+			walk(n.body, inner)
+		}
+	}
+	walk(n, nil)
+	return res
+}
+
+// depsInfo describes dependencies of variable name
+func (sess *Session) depsInfo(name string) (string, error) {
+	graph := sess.graph
+	val, ok := sess.memory[name]
+	if !ok {
+		return "", newErr(ErrUndefined, name)
+	}
+	none := func(l []string) string {
+		if len(l) == 0 {
+			return "-"
+		}
+		return strings.Join(l, ", ")
+	}
+	lines := []string{
+		Format(VarNode{ident: Token{ttype: tIDENT, val: name}, val: val}),
+		"depends on: " + none(graph.dependencies(name, false)),
+		"all inputs: " + none(graph.dependencies(name, true)),
+		"used by:    " + none(graph.dependents(name, false)),
+		"affects:    " + none(graph.dependents(name, true)),
+	}
+	return strings.Join(lines, "\n"), nil
+}
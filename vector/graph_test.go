@@ -0,0 +1,84 @@
+package vector
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// runAll runs lines in new session
+func runAll(t *testing.T, lines ...string) *Session {
+	sess := NewSession()
+	for _, l := range lines {
+		if _, err := sess.Run(context.Background(), l); err != nil {
+			t.Fatalf("Run(%q): %v", l, err)
+		}
+	}
+	return sess
+}
+
+func TestDependencies(t *testing.T) {
+	sess := runAll(t, "a = 1", "b = a + 1", "c = b * a", "d = c + e", "f = x -> x + c")
+	g := sess.graph
+	tests := []struct {
+		name string
+		fn   func(string, bool) []string
+		all  bool
+		want []string
+	}{
+		{"b", g.dependencies, false, []string{"a"}},
+		{"c", g.dependencies, false, []string{"a", "b"}},
+		{"d", g.dependencies, true, []string{"a", "b", "c", "e"}},
+		{"a", g.dependents, false, []string{"b", "c"}},
+		{"a", g.dependents, true, []string{"b", "c", "d", "f"}},
+		{"f", g.dependencies, false, []string{"c"}},
+		{"d", g.dependents, true, nil},
+	}
+	for _, tt := range tests {
+		if got := tt.fn(tt.name, tt.all); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s all=%v: got %v, want %v", tt.name, tt.all, got, tt.want)
+		}
+	}
+}
+
+func TestCycle(t *testing.T) {
+	tests := []struct {
+		setup []string
+		src   string
+	}{
+		{nil, "a = a + 1"},
+		{[]string{"a = 1", "b = a"}, "a = b"},
+		{[]string{"a = 1", "b = a", "c = b * 2"}, "a = c"},
+		{[]string{"f = x -> x"}, "f = x -> f(x)"},
+	}
+	for _, tt := range tests {
+		sess := runAll(t, tt.setup...)
+		if _, err := sess.Run(context.Background(), tt.src); !errors.Is(err, ErrCycle) {
+			t.Errorf("%v then %q = %v, want %v", tt.setup, tt.src, err, ErrCycle)
+		}
+	}
+	// eager assignment stores value, not formula
+	runAll(t, "a = 1", "a := a + 1")
+}
+
+func TestInvalidate(t *testing.T) {
+	sess := runAll(t, "a = 1", "b = a * 10", "c = b + 1")
+	for _, tt := range []struct{ src, want string }{
+		{"c", "11"},
+		{"a = 2", ""},
+		{"c", "21"},
+		{"b := 5", ""},
+		{"c", "6"},
+		{"a = 100", ""},
+		{"c", "6"},
+	} {
+		res, err := sess.Run(context.Background(), tt.src)
+		if err != nil {
+			t.Fatalf("Run(%q): %v", tt.src, err)
+		}
+		if tt.want != "" && Format(res) != tt.want {
+			t.Errorf("Run(%q) = %s, want %s", tt.src, Format(res), tt.want)
+		}
+	}
+}
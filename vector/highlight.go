@@ -0,0 +1,121 @@
+package vector
+
+import (
+	"sort"
+	"strings"
+)
+
+const (
+	cRESET   = "\x1b[0m"
+	cNUM     = "\x1b[36m"
+	cIDENT   = "\x1b[33m"
+	cKEYW    = "\x1b[1;35m"
+	cFUNC    = "\x1b[34m"
+	cOP      = "\x1b[37m"
+	cBRACKET = "\x1b[1m"
+	cERR     = "\x1b[31m"
+)
+
+// tokenColor returns terminal color of token type
+func tokenColor(tt TokenType) string {
+	switch tt {
+	case tNUM:
+		return cNUM
+	case tIDENT:
+		return cIDENT
+	case tKEYW:
+		return cKEYW
+	case tFUNC:
+		return cFUNC
+	case tLPAREN, tRPAREN, tLVECPAR, tRVECPAR, tABS:
+		return cBRACKET
+	case tSPACE:
+		return ""
+	case tBAD:
+		return cERR
+	}
+	return cOP
+}
+
+// Highlight colors src for terminals using tokens of lexer
+func Highlight(src string) string {
+	l := NewLexer(src)
+	std.mu.Lock()
+	l.comma = std.lang.commaDecimal()
+	std.mu.Unlock()
+	l.GenerateTokens()
+
+	var b strings.Builder
+	last := 0
+	for _, t := range l.tokens {
+		end := t.pos + len(t.val)
+		if t.pos < last || end > len(src) {
+			continue
+		}
+		b.WriteString(src[last:t.pos])
+		if c := tokenColor(t.ttype); c != "" {
+			b.WriteString(c + src[t.pos:end] + cRESET)
+		} else {
+			b.WriteString(src[t.pos:end])
+		}
+		last = end
+	}
+	b.WriteString(src[last:])
+	return b.String()
+}
+
+// Complete returns keywords, functions, variables and constants starting with prefix
+func Complete(prefix string) []string {
+	return std.Complete(prefix)
+}
+
+// Complete returns keywords, functions, variables of sess and constants starting with prefix
+func (sess *Session) Complete(prefix string) []string {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	seen := map[string]bool{}
+	var res []string
+	add := func(name string) {
+		if strings.HasPrefix(name, prefix) && !seen[name] {
+			seen[name] = true
+			res = append(res, name)
+		}
+	}
+	for _, kw := range keywords {
+		add(kw.name)
+		for _, a := range kw.alias {
+			add(a)
+		}
+	}
+	funcMu.RLock()
+	for fn := range functions {
+		add(string(fn))
+	}
+	funcMu.RUnlock()
+	for name := range sess.memory {
+		add(name)
+	}
+	for name := range constants {
+		add(name)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// Kind returns keyword, func, const or var for name, empty if name is unknown to sess
+func (sess *Session) Kind(name string) string {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	switch {
+	case isKeyword(name):
+		return "keyword"
+	case isFunc(name):
+		return "func"
+	}
+	if _, ok := constants[name]; ok {
+		return "const"
+	} else if _, ok := sess.memory[name]; ok {
+		return "var"
+	}
+	return ""
+}
@@ -0,0 +1,60 @@
+package vector
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestHighlight(t *testing.T) {
+	c := func(color, s string) string {
+		return color + s + cRESET
+	}
+	tests := []struct {
+		src, want string
+	}{
+		{"1 + a", c(cNUM, "1") + " " + c(cOP, "+") + " " + c(cIDENT, "a")},
+		{"sin(x)", c(cFUNC, "sin") + c(cBRACKET, "(") + c(cIDENT, "x") + c(cBRACKET, ")")},
+		{"vars", c(cKEYW, "vars")},
+		{"[1 2]", c(cBRACKET, "[") + c(cNUM, "1") + " " + c(cNUM, "2") + c(cBRACKET, "]")},
+		{"|a| || b", c(cBRACKET, "|") + c(cIDENT, "a") + c(cBRACKET, "|") + " " + c(cOP, "||") + " " + c(cIDENT, "b")},
+		{"1 $ 2", c(cNUM, "1") + " " + c(cERR, "$") + " " + c(cNUM, "2")},
+		// unterminated input keeps its text
+		{"(1 + ", c(cBRACKET, "(") + c(cNUM, "1") + " " + c(cOP, "+") + " "},
+		{"0b12", c(cERR, "0b12")},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := Highlight(tt.src); got != tt.want {
+			t.Errorf("Highlight(%q) = %q, want %q", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestComplete(t *testing.T) {
+	sess := NewSession()
+	for _, src := range []string{"delta = 1", "sinus = 2", "e2 = 3"} {
+		if _, err := sess.Run(context.Background(), src); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		{"de", []string{"del", "delete", "delta", "deps"}},
+		{"sin", []string{"sin", "sinus"}},
+		{"e", []string{"e", "e2", "end", "eps", "exit", "explain", "export"}},
+		{"pi", []string{"pi"}},
+		{"zz", nil},
+	}
+	for _, tt := range tests {
+		if got := sess.Complete(tt.prefix); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Complete(%q) = %q, want %q", tt.prefix, got, tt.want)
+		}
+	}
+	// variables of other sessions are not offered
+	if got := NewSession().Complete("delt"); got != nil {
+		t.Errorf("Complete in new session = %q, want none", got)
+	}
+}
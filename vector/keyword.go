@@ -15,12 +15,27 @@ func (k keyWord) getNameByAlias(al string) string {
 }
 
 var (
-	kwVEC    = keyWord{name: "vec"}
-	kwQUIT   = keyWord{name: "quit", alias: []string{"end", "exit", "close"}}
-	kwCLEAR  = keyWord{name: "clear", alias: []string{"cls"}}
-	kwHELP   = keyWord{name: "help"}
-	kwANS    = keyWord{name: "ans"}
-	kwEXPORT = keyWord{name: "export", alias: []string{"save"}}
+	kwVEC     = keyWord{name: "vec"}
+	kwQUIT    = keyWord{name: "quit", alias: []string{"end", "exit", "close"}}
+	kwCLEAR   = keyWord{name: "clear", alias: []string{"cls"}}
+	kwHELP    = keyWord{name: "help"}
+	kwANS     = keyWord{name: "ans"}
+	kwEXPORT  = keyWord{name: "export", alias: []string{"save"}}
+	kwHISTORY = keyWord{name: "history"}
+	kwFORMAT  = keyWord{name: "format"}
+	kwDEPS    = keyWord{name: "deps"}
+	kwVARS    = keyWord{name: "vars"}
+	kwDEL     = keyWord{name: "del", alias: []string{"delete"}}
+	kwRESET   = keyWord{name: "reset"}
+	kwSHOW    = keyWord{name: "show"}
+	kwTRUE    = keyWord{name: "true"}
+	kwFALSE   = keyWord{name: "false"}
+	kwEPS     = keyWord{name: "eps"}
+	kwASSERT  = keyWord{name: "assert"}
+	kwTRACE   = keyWord{name: "trace"}
+	kwAST     = keyWord{name: "ast"}
+	kwEXPLAIN = keyWord{name: "explain"}
+	kwLANG    = keyWord{name: "lang"}
 )
 
 var keywords = []keyWord{
@@ -30,6 +45,21 @@ var keywords = []keyWord{
 	kwHELP,
 	kwANS,
 	kwEXPORT,
+	kwHISTORY,
+	kwFORMAT,
+	kwDEPS,
+	kwVARS,
+	kwDEL,
+	kwRESET,
+	kwSHOW,
+	kwTRUE,
+	kwFALSE,
+	kwEPS,
+	kwASSERT,
+	kwTRACE,
+	kwAST,
+	kwEXPLAIN,
+	kwLANG,
 }
 
 func isKeyword(str string) bool {
@@ -0,0 +1,145 @@
+package vector
+
+import "fmt"
+
+// LambdaNode is anonymous function, free variables are bound when it is resolved,
+// so f = x -> x + a reads a again on each use of f while f := x -> x + a keeps a
+type LambdaNode struct {
+	params []string
+	body   Node
+}
+
+func (n LambdaNode) resolve(s *state) (Node, error) {
+	vals := Memory{}
+	for _, name := range refs(n) {
+		if !captures(s, name) {
+			continue
+		}
+		v, err := s.resolve(VarNode{ident: Token{ttype: tIDENT, val: name}})
+		if err != nil {
+			return nil, err
+		}
+		vals[name] = v
+	}
+	if len(vals) == 0 {
+		return n, nil
+	}
+	return substitute(n, vals, nil), nil
+}
+
+// call binds resolved args to params and resolves body
+func (n LambdaNode) call(s *state, args []Node) (Node, error) {
+	if len(args) != len(n.params) {
+		return nil, newErr(ErrLambdaArity, Format(n), arguments(len(n.params)))
+	}
+	scope := Memory{}
+	for i, p := range n.params {
+		arg, err := s.resolve(args[i])
+		if err != nil {
+			return nil, err
+		}
+		scope[p] = arg
+	}
+	s.scopes = append(s.scopes, scope)
+	// body may come from stored lambda
+	s.foreign++
+	defer func() {
+		s.scopes = s.scopes[:len(s.scopes)-1]
+		s.foreign--
+	}()
+	return s.resolve(n.body)
+}
+
+// String shows lambda in input syntax as it is also a value
+func (n LambdaNode) String() string {
+	return Format(n)
+}
+
+// CallNode applies function value to args
+type CallNode struct {
+	fn   Node
+	args []Node
+}
+
+func (n CallNode) resolve(s *state) (Node, error) {
+	fn, err := s.resolve(n.fn)
+	if err != nil {
+		return nil, err
+	}
+	l, ok := fn.(LambdaNode)
+	if !ok {
+		return nil, newErr(ErrCall, typeName(fn), Format(n))
+	}
+	return l.call(s, n.args)
+}
+
+func (n CallNode) String() string {
+	return fmt.Sprintf("call:%s(%v)", n.fn, n.args)
+}
+
+// captures reports if lambda defined in s binds name, constants never change and stay by name
+func captures(s *state, name string) bool {
+	for _, scope := range s.scopes {
+		if _, ok := scope[name]; ok {
+			return true
+		}
+	}
+	_, ok := s.sess.memory[name]
+	return ok
+}
+
+// substitute replaces variables not in bound by vals
+func substitute(n Node, vals Memory, bound map[string]bool) Node {
+	sub := func(n Node) Node {
+		return substitute(n, vals, bound)
+	}
+	subAll := func(ns []Node) []Node {
+		res := make([]Node, len(ns))
+		for i, n := range ns {
+			res[i] = sub(n)
+		}
+		return res
+	}
+
+	switch n := n.(type) {
+	case VarNode:
+		if n.val != nil {
+			n.val = sub(n.val)
+		} else if v, ok := vals[n.ident.val]; ok && !bound[n.ident.val] {
+			return v
+		}
+		return n
+	case UnaryNode:
+		n.node = sub(n.node)
+		return n
+	case OperationNode:
+		n.left, n.right = sub(n.left), sub(n.right)
+		return n
+	case FuncNode:
+		n.args = subAll(n.args)
+		return n
+	case VecNode:
+		n.fields = subAll(n.fields)
+		return n
+	case ListNode:
+		n.items = subAll(n.items)
+		return n
+	case IndexNode:
+		n.node, n.index = sub(n.node), sub(n.index)
+		return n
+	case CallNode:
+		n.fn, n.args = sub(n.fn), subAll(n.args)
+		return n
+	case LambdaNode:
+		inner := map[string]bool{}
+		for name := range bound {
+			inner[name] = true
+		}
+		for _, p := range n.params {
+			inner[p] = true
+		}
+		n.body = substitute(n.body, vals, inner)
+		return n
+	}
+	return n
+}
@@ -0,0 +1,33 @@
+package vector
+
+import (
+	"context"
+	"testing"
+)
+
+func TestLambdaCapture(t *testing.T) {
+	tests := []struct {
+		assign string
+		want   string
+	}{
+		// lazy lambda binds a when f is used
+		{"f = x -> x + a", "2"},
+		// eager lambda keeps a of assignment
+		{"f := x -> x + a", "1"},
+	}
+	for _, tt := range tests {
+		sess := NewSession()
+		for _, src := range []string{"a = 1", tt.assign, "a = 2"} {
+			if _, err := sess.Run(context.Background(), src); err != nil {
+				t.Fatalf("%s: %v", src, err)
+			}
+		}
+		res, err := sess.Run(context.Background(), "f(0)")
+		if err != nil {
+			t.Fatalf("f(0) after %s: %v", tt.assign, err)
+		}
+		if got := Format(res); got != tt.want {
+			t.Errorf("f(0) after %s = %s, want %s", tt.assign, got, tt.want)
+		}
+	}
+}
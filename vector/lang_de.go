@@ -0,0 +1,244 @@
+package vector
+
+// german shows messages in German and numbers with decimal comma
+var german = &locale{
+	name:       "de",
+	decimal:    ',',
+	group:      '.',
+	codes:      codesDE,
+	hints:      hintsDE,
+	categories: [...]string{"Syntax", "Mathematik", "Typ", "Name", "Argument", "Grenze", "Einstellung", "AST", "Annahme", "Implementierung"},
+	words: map[word]string{
+		"expression": "Ausdruck",
+		"; or )":     "; oder )",
+		"; or }":     "; oder }",
+	},
+	one:  "Argument",
+	many: "Argumente",
+	help: helpTextDE,
+}
+
+var codesDE = map[Code]codeInfo{
+	ErrCharacter: {"ungültiges Zeichen", "Ungültiges Zeichen: %s",
+		"Die Eingabe enthält ein Zeichen, das nicht zur Sprache gehört, etwa $ oder ein einzelnes & oder ~."},
+	ErrNumber: {"ungültige Zahl", "%s ist keine Zahl",
+		"Eine Zahl hat mehr als ein Dezimaltrennzeichen, Ziffern außerhalb ihrer Basis wie 0b12 oder _ nicht zwischen Ziffern. Schreibe 1,5, 1_000 oder 0x1F."},
+	ErrExpected: {"unerwartetes Zeichen", "%s erwartet",
+		"Der Parser hat an dieser Stelle etwas anderes gefunden, als die Sprache erlaubt, etwa eine fehlende schließende Klammer vor weiterer Eingabe."},
+	ErrIncomplete: {"unvollständige Eingabe", "%s erwartet",
+		"Die Eingabe endet, bevor der Ausdruck vollständig ist. Die REPL fragt nach einer weiteren Zeile, eine leere Zeile bricht ab."},
+	ErrChained: {"verkettete Vergleiche", "Vergleiche können nicht verkettet werden",
+		"a < b < c ist nicht erlaubt, weil unklar ist, was gemeint ist. Verbinde Vergleiche mit && wie a < b && b < c."},
+	ErrNestedAssign: {"Zuweisung in Zuweisung", "Variable kann nicht in einer Zuweisung zugewiesen werden",
+		"Der Wert einer Zuweisung kann keine weitere Variable zuweisen, wie a = b = 1. Weise jede Variable einzeln zu."},
+	ErrVecInVec: {"Vec in Vec", "Vec in Vec ist nicht erlaubt",
+		"Felder eines Vecs sind Zahlen. Mehrere Vecs fasst eine Liste wie {vec(1 2); vec(3 4)} zusammen."},
+	ErrAssignInVec: {"Zuweisung in Vec", "Variable kann nicht in Vec zugewiesen werden",
+		"Felder eines Vecs sind Ausdrücke, weise Variablen vor dem Erstellen des Vecs zu."},
+	ErrDuplicateParam: {"doppelter Parameter", "Parameter %s kommt doppelt vor",
+		"Jeder Parameter eines Lambdas braucht einen eigenen Namen, (x; x) -> x ist nicht erlaubt."},
+	ErrExpectedVar: {"Variable erwartet", "Variable nach %s erwartet",
+		"Schlüsselwörter wie deps, show und del nehmen den Namen einer Variable, etwa show a."},
+	ErrExpectedParen: {"Klammer erwartet", "( nach %s erwartet",
+		"Funktionen werden mit ihren Argumenten in Klammern aufgerufen, etwa sin(pi)."},
+	ErrDecimalComma: {"Dezimalkomma", "Ungültiges Zeichen: ,",
+		"Mit lang en werden Zahlen mit Dezimalpunkt wie 1.5 geschrieben. Mit lang de werden 1,5 und 1.5 gelesen."},
+
+	ErrDivisionByZero: {"Division durch Null", "Division durch Null",
+		"Eine Zahl oder ein Feld eines Vecs wurde durch 0 geteilt, das hat kein Ergebnis."},
+	ErrNegativeRoot: {"negative Wurzel", "Negative Zahl in Wurzel",
+		"Wurzeln negativer Zahlen sind keine reellen Zahlen, vector rechnet nur mit reellen Zahlen."},
+	ErrDivideByVec: {"Division durch Vec", "Division durch Vec nicht möglich: %s",
+		"Vecs können durch Zahlen geteilt werden, aber nichts kann durch einen Vec geteilt werden."},
+	ErrVecPower: {"Potenz von Vec", "Potenz für Vec nicht implementiert: %s",
+		"Potenzen und Wurzeln gibt es nur für Zahlen, * bildet Produkte von Vecs."},
+
+	ErrType: {"unerwarteter Typ", "Unerwarteter Typ: %s",
+		"Der Operator passt nicht zu den Typen seiner Operanden, vars zeigt den Typ jeder Variable."},
+	ErrAdd: {"Addition nicht möglich", "%s und %s können nicht addiert werden: %s",
+		"Nur Zahlen können zu Zahlen addiert werden und Vecs zu Vecs, der kürzere Vec wird mit Nullen aufgefüllt."},
+	ErrSubtract: {"Subtraktion nicht möglich", "%s und %s können nicht subtrahiert werden: %s",
+		"Nur Zahlen können von Zahlen subtrahiert werden und Vecs von Vecs, der kürzere Vec wird mit Nullen aufgefüllt."},
+	ErrCompare: {"Vergleich nicht möglich", "%s und %s können nicht verglichen werden: %s",
+		"== und != vergleichen Werte gleichen Typs, ~= vergleicht Zahlen oder Vecs."},
+	ErrOrder: {"Ordnung nicht möglich", "%s und %s können nicht geordnet werden: %s",
+		"<, >, <= und >= vergleichen nur Zahlen."},
+	ErrBool: {"Bool erwartet", "Bool erwartet, %s erhalten: %s",
+		"!, && und || nehmen Bools, die aus Vergleichen oder true und false entstehen."},
+	ErrCall: {"nicht aufrufbar", "%s kann nicht aufgerufen werden: %s",
+		"Nur Funktionen und Lambdas wie x -> x * 2 können aufgerufen werden."},
+	ErrIndex: {"nicht indizierbar", "%s kann nicht indiziert werden: %s",
+		"Nur Listen und Vecs haben Elemente, die per Index wie v[1] gelesen werden."},
+	ErrStore: {"Speichern nicht möglich", "%s kann nicht in %s gespeichert werden",
+		"Vecs enthalten Zahlen, Listen enthalten Zahlen, Bools, Vecs und Listen."},
+	ErrAssertion: {"Typ von assert", "assert erwartet Bool, %s erhalten: %s",
+		"assert prüft eine Bedingung wie a == 3, andere Werte können nicht wahr oder falsch sein."},
+	ErrNoValue: {"kein Wert", "Kein Wert für %s",
+		"Die Eingabe hat keinen Wert, der in Go benutzt werden kann, etwa die Ausgabe eines Schlüsselworts."},
+	ErrNoOperand: {"fehlender Operand", "Operand von %s hat keinen Wert",
+		"Der Operand des Operators hat keinen Wert, etwa eine Zuweisung, die nichts zurückgibt."},
+
+	ErrUndefined: {"nicht definiert", "%s ist nicht definiert",
+		"Die Variable oder Funktion wurde nie zugewiesen oder wurde gelöscht. vars listet alle Variablen."},
+	ErrConstant: {"Konstante", "%s ist eine Konstante",
+		"Konstanten wie pi und e können nicht zugewiesen werden, wähle einen anderen Namen."},
+	ErrCycle: {"Zyklus", "Zyklus %s",
+		"Formeln, die sich selbst lesen, vielleicht über andere Variablen, enden nie. := speichert den aktuellen Wert statt der Formel."},
+	ErrVarName: {"ungültiger Variablenname", "%s ist kein gültiger Variablenname",
+		"Namen beginnen mit einem Buchstaben und enthalten Buchstaben, Ziffern und _, sie können keine Schlüsselwörter sein."},
+	ErrFuncName: {"ungültiger Funktionsname", "%s ist kein gültiger Funktionsname",
+		"Namen beginnen mit einem Buchstaben und enthalten Buchstaben, Ziffern und _, sie können keine Schlüsselwörter sein."},
+	ErrDeleteConstant: {"Konstante löschen", "Konstante %s kann nicht gelöscht werden",
+		"Konstanten wie pi und e gibt es immer."},
+	ErrInUse: {"Variable in Benutzung", "%s kann nicht gelöscht werden, benutzt von %s",
+		"Andere Formeln lesen die Variable, lösche oder ändere sie zuerst. deps zeigt, wer eine Variable benutzt."},
+	ErrUnused: {"unbenutzte Eingabe", "%s wird vom Programm nicht benutzt",
+		"Das kompilierte Programm liest diese Variable nicht, sie zu setzen hat keine Wirkung."},
+	ErrUnknownCode: {"unbekannter Code", "Unbekannter Fehlercode %s",
+		"Codes sind E und drei Ziffern wie E101, explain ohne Code listet alle Codes."},
+	ErrBuiltin: {"eingebaute Funktion", "%s ist eine eingebaute Funktion",
+		"Aus Go registrierte Funktionen können Funktionen wie sin oder map nicht ersetzen, wähle einen anderen Namen."},
+
+	ErrArity: {"falsche Anzahl Argumente", "%s erwartet %s",
+		"Die Funktion wurde mit zu vielen oder zu wenigen Argumenten aufgerufen, help listet alle Funktionen."},
+	ErrLambdaArity: {"falsche Anzahl Argumente", "Funktion %s erwartet %s",
+		"Ein Lambda nimmt genau ein Argument für jeden seiner Parameter."},
+	ErrArgNumber: {"Zahl erwartet", "%s erwartet Zahl",
+		"Die Funktion rechnet mit einer Zahl, etwa sin(pi)."},
+	ErrArgItems: {"Liste erwartet", "%s erwartet Liste oder Vec",
+		"Die Funktion arbeitet mit den Elementen einer Liste oder den Feldern eines Vecs."},
+	ErrArgFunc: {"Funktion erwartet", "%s erwartet Funktion wie x -> x * 2",
+		"Die Funktion wendet ein Lambda oder eine Funktion auf jedes Element an."},
+	ErrReduceArgs: {"Argumente von reduce", "reduce erwartet Liste, optionalen Startwert und Funktion",
+		"reduce({1; 2; 3}; (a; b) -> a + b) verbindet Elemente von links nach rechts, reduce(liste; start; f) beginnt mit start."},
+	ErrReduceEmpty: {"reduce einer leeren Liste", "reduce einer leeren Liste braucht Startwert",
+		"Eine leere Liste hat kein erstes Element zum Beginnen, gib einen Startwert wie reduce(liste; 0; f) an."},
+	ErrIfArgs: {"Argumente von if", "if erwartet Bedingung und Wert",
+		"if nimmt Paare aus Bedingung und Wert, optional gefolgt von einem Wert, wenn keine Bedingung wahr ist."},
+	ErrIfNone: {"keine wahre Bedingung", "Keine Bedingung von if ist wahr",
+		"Keine Bedingung von if ist wahr und es gibt keinen Wert für sonst als letztes Argument."},
+	ErrIndexWhole: {"Index nicht ganz", "Index muss ganze Zahl sein: %s",
+		"Elemente werden 1, 2, 3, ... gezählt, es gibt kein Element 1,5."},
+	ErrIndexRange: {"Index außerhalb", "Index %s außerhalb von 1..%d",
+		"Elemente werden von 1 bis len(liste) gezählt."},
+	ErrApproxArgs: {"Argumente von approx", "approx erwartet 2 bis 4 Argumente",
+		"approx(a; b) vergleicht mit eps, approx(a; b; abs) und approx(a; b; abs; rel) benutzen eigene Toleranzen."},
+	ErrApproxTol: {"Toleranz von approx", "approx erwartet Toleranz als Zahl >= 0",
+		"Toleranzen sind Zahlen, die 0 oder größer sind."},
+	ErrApproxKind: {"Werte von approx", "approx erwartet zwei Zahlen oder zwei Vecs",
+		"approx vergleicht Zahlen mit Zahlen und Vecs mit Vecs gleicher Länge."},
+	ErrNoResult: {"kein Ergebnis", "%s hat keinen Wert zurückgegeben",
+		"Eine aus Go registrierte Funktion hat nil ohne Fehler zurückgegeben."},
+	ErrArgWhole: {"ganze Zahl erwartet", "%s erwartet ganze Zahl: %s",
+		"Nur ganze Zahlen werden in anderen Basen gezeigt, etwa hex(255)."},
+	ErrArgRange: {"Zahl zu groß", "%s erwartet Zahl mit Betrag unter 2^63: %s",
+		"Andere Basen zeigen nur Zahlen mit 63 Bits, etwa hex(2^62)."},
+
+	ErrDepth: {"zu tief", "Maximale Tiefe von %d überschritten",
+		"Die Auswertung ist zu tief verschachtelt, oft wegen eines Lambdas, das sich endlos selbst aufruft."},
+	ErrSteps: {"zu viele Schritte", "Maximum von %d Schritten überschritten",
+		"Die Auswertung dauert zu lange und wurde angehalten."},
+	ErrLength: {"Vec zu lang", "Maximale Vec-Länge von %d überschritten",
+		"Der Vec wurde länger als erlaubt."},
+	ErrCanceled: {"abgebrochen", "Auswertung abgebrochen: %v",
+		"Die Auswertung wurde von außen angehalten, etwa durch das Zeitlimit des Servers."},
+
+	ErrOption: {"unbekannte Option", "Unbekannte Option von %s: %s",
+		"Das Schlüsselwort kennt diese Option nicht, help listet alle Optionen."},
+	ErrOptionValue: {"Wert fehlt", "Wert nach %s erwartet",
+		"Die Option braucht einen Wert, etwa format digits 4 oder eps abs 0,01."},
+	ErrDigits: {"ungültige Stellen", "Stellen müssen zwischen 0 und 17 liegen",
+		"Zahlen werden mit höchstens 17 Stellen gezeigt, mehr Stellen gibt es in einem float64 nicht."},
+	ErrOnOff: {"on oder off erwartet", "on oder off erwartet",
+		"Schalter wie frac und group werden mit on oder off gesetzt, etwa format frac on."},
+	ErrTolerance: {"ungültige Toleranz", "Toleranz muss Zahl >= 0 sein",
+		"Toleranzen von ~= sind Zahlen, die 0 oder größer sind."},
+	ErrLang: {"unbekannte Sprache", "Unbekannte Sprache %s, wähle eine von %s",
+		"Meldungen und Zahlen werden in en oder de gezeigt, etwa lang de."},
+	ErrBase: {"ungültige Basis", "Basis muss 2, 8, 10 oder 16 sein",
+		"Ganze Zahlen werden binär, oktal, dezimal oder hexadezimal gezeigt, etwa format base 16."},
+
+	ErrASTMissing: {"AST-Knoten fehlt", "Fehlender Knoten im AST",
+		"Ein Kind eines Knotens im AST-JSON ist null."},
+	ErrASTChildren: {"Kinder im AST", "AST-Knoten %s erwartet %d Kinder",
+		"Der Knoten hat eine falsche Anzahl Kinder, ast json 'ausdruck' zeigt gültige Bäume."},
+	ErrASTField: {"AST-Feld fehlt", "AST-Knoten %s braucht %s",
+		"Dem Knoten fehlt ein Feld, das zum Aufbau nötig ist."},
+	ErrASTVar: {"Variablenname im AST", "Ungültiger Variablenname im AST: %s",
+		"Namen beginnen mit einem Buchstaben und enthalten Buchstaben, Ziffern und _."},
+	ErrASTParam: {"Parametername im AST", "Ungültiger Parametername im AST: %s",
+		"Namen beginnen mit einem Buchstaben und enthalten Buchstaben, Ziffern und _."},
+	ErrASTCommand: {"Befehl im AST", "Unbekannter Befehl im AST: %s",
+		"cmd-Knoten nennen Schlüsselwörter wie vars oder export."},
+	ErrASTType: {"AST-Knotentyp", "Unbekannter AST-Knotentyp: %s",
+		"Typen sind num, bool, vec, list, unary, op, var, assign, func, call, index, lambda, assert, trace, ast und cmd."},
+	ErrASTOperator: {"AST-Operator", "Ungültiger Operator im AST: %s",
+		"op enthält einen Operator der Sprache wie + oder <=."},
+	ErrASTJSON: {"AST-JSON", "Ungültiges AST-JSON: %s",
+		"Der AST ist kein gültiges JSON."},
+
+	ErrAssert: {"Annahme verletzt", "Annahme verletzt: %s",
+		"Die angenommene Bedingung ist falsch, beide Seiten eines Vergleichs stehen in Klammern."},
+
+	ErrKeyword: {"Schlüsselwort nicht implementiert", "Schlüsselwort nicht implementiert",
+		"Das Schlüsselwort ist reserviert, tut aber noch nichts."},
+	ErrOperator: {"Operator nicht implementiert", "Unärer Operator nicht implementiert: %s",
+		"Der Operator kann nicht vor einen Wert gestellt werden."},
+	ErrNotImplemented: {"nicht implementiert", "Nicht implementiert: %s",
+		"Die Operation ist für diese Werte nicht implementiert."},
+	ErrCompile: {"nicht kompilierbar", "Kompilieren nicht möglich: %s",
+		"Kompilierte Programme enthalten nur Zahlen, Variablen, Operatoren und Funktionen einer Zahl."},
+	ErrCompileAssign: {"Zuweisung nicht kompilierbar", "Zuweisung kann nicht kompiliert werden: %s",
+		"Kompilierte Programme können keine Variablen zuweisen, setze Eingaben stattdessen aus Go."},
+}
+
+var hintsDE = map[Code]string{
+	ErrChained:      "benutze && wie a < b && b < c",
+	ErrVecInVec:     "fasse Vecs in einer Liste wie {vec(1 2); vec(3 4)} zusammen",
+	ErrDecimalComma: "schreibe 1.5 oder wechsle mit lang de zum Dezimalkomma",
+	ErrCycle:        "benutze := um den aktuellen Wert zuzuweisen",
+	ErrUnknownCode:  "Codes sehen aus wie E101, explain listet alle Codes",
+	ErrReduceEmpty:  "gib einen Startwert wie reduce(liste; 0; f) an",
+	ErrConstant:     "pi und e sind Konstanten, benenne solche Variablen um, etwa e1 = ...",
+}
+
+// helpTextDE is shown by help keyword with lang de
+const helpTextDE = `HILFE
+Variable zuweisen:      $ 'name' = 'ausdruck'
+Wert zuweisen:          $ 'name' := 'ausdruck'
+Abhängigkeiten zeigen:  $ deps 'name'
+Variablen:              $ vars | $ show 'name' | $ del 'name' | $ reset
+Konstanten:             pi | e  (nur lesbar, Skripte mit e = ... oder pi = ... brauchen einen anderen Namen)
+Programm beenden:       $ quit | $ close | $ end | $ exit
+Vektor erstellen:       $ vec('x' 'y' 'z' ...) | ['x' 'y' 'z' ...] | vec('x';'y';'z';...) | ['x';'y';'z';...]
+Liste erstellen:        $ {'a'; 'b'; ...}
+Index:                  $ 'liste'['i'] | 'vec'['i']  (gezählt ab 1)
+Lambda:                 $ x -> 'ausdruck' | (a; b) -> 'ausdruck'  (f = ... liest andere Variablen bei jeder Nutzung, f := ... behält ihre Werte)
+Funktion aufrufen:      $ 'name'('a'; 'b') | (x -> 'ausdruck')('a')
+Variablen exportieren:  $ export | $ save
+Verlauf zeigen:         $ history
+Zahlenformat:           $ format [fix | sci | eng] [digits 'n'] [frac on | off] [group on | off] [base 2 | 8 | 10 | 16] | $ format reset
+Zahlen:                 1_000_000 | 0x1F | 0b1010 | 0o17
+Annahme:                $ assert 'bedingung'  (Dateien prüfen mit: vec test 'datei' ...)
+Syntaxbaum:             $ ast [json | dot] 'ausdruck'  (oder Start mit --dump-ast=json|dot)
+Auswertung verfolgen:   $ trace 'ausdruck'  (oder Start mit --trace)
+Toleranz:               $ eps [abs 'x'] [rel 'x'] | $ eps 'x' | $ eps reset
+Fehler erklären:        $ explain 'code' | $ explain  (Fehler beginnen mit Codes wie E101)
+Sprache:                $ lang [en | de] | $ lang reset  (de zeigt 1,5 und liest 1,5 und 1.5)
+
+Operatoren:
+	Addieren:       '+'
+	Subtrahieren:   '-'
+	Multiplizieren: '*'
+	Dividieren:     '/' | ':'
+	Potenz:         '^'
+	Wurzel:         '\'
+	Vergleich:      '<' | '>' | '<=' | '>=' | '==' | '!=' | '~=' | '≈'
+	Logik:          '&&' | '||' | '!'  mit true | false
+
+Funktionen:
+	sin('x') | cos('x') | tan('x') | log('x') | ln('x')
+	len('liste') | sum('liste') | map('liste'; 'lambda') | filter('liste'; 'lambda') | reduce('liste'; ['start';] 'lambda')
+	if('bed'; 'a'; ['bed2'; 'b'; ...] 'sonst')  (nur der gewählte Zweig wird ausgewertet)
+	approx('a'; 'b'; ['abs'; ['rel']])  (Toleranzen sind standardmäßig eps)
+	hex('x') | bin('x') | oct('x')  (zeigt ganze Zahl in Basis 16, 2 oder 8)`
@@ -2,6 +2,7 @@ package vector
 
 import (
 	"strings"
+	"unicode/utf8"
 )
 
 const (
@@ -11,50 +12,111 @@ const (
 	sDIGITS  = "0123456789"
 )
 
-// Lexer lexes given input
+// Lexer lexes given input, pos is byte offset of char which is width bytes long
 type Lexer struct {
 	text       string
 	pos        int
+	width      int
 	char       rune
 	tokens     []Token
+	start      int
 	inVec      bool
 	paranDepth int
+	// abs counts bars opened but not closed yet inside current brackets,
+	// outerAbs those of enclosing brackets
+	abs      int
+	outerAbs []int
+	// comma is read as decimal separator like point
+	comma bool
+	errs  ErrorList
 }
 
 // NewLexer returns new Lexer
 func NewLexer(input string) *Lexer {
-	l := &Lexer{text: input, pos: -1}
+	l := &Lexer{text: input, pos: -1, width: 1}
 	l.advance()
 	return l
 }
 
 func (l *Lexer) advance() {
-	l.pos++
+	l.pos += l.width
 	if l.pos >= len(l.text) {
-		l.char = 0
+		l.char, l.width = 0, 1
 		return
 	}
-	l.char = rune(l.text[l.pos])
+	l.char, l.width = utf8.DecodeRuneInString(l.text[l.pos:])
 }
 
-func (l *Lexer) makeNum() error {
+// peekIs reports if char after current one is c
+func (l *Lexer) peekIs(c byte) bool {
+	next := l.pos + l.width
+	return next < len(l.text) && l.text[next] == c
+}
+
+// bad reports err and keeps its input as tBAD token so lexing goes on
+func (l *Lexer) bad(err Error) {
+	l.errs = append(l.errs, err)
+	l.tokens = append(l.tokens, Token{ttype: tBAD, val: l.text[err.Span.Start:err.Span.End], pos: err.Span.Start})
+}
+
+// afterOperand reports if first n tokens end with operand, then a binary operator
+// may follow and || is or, else it opens two abs like ||x| - 1|
+func (l *Lexer) afterOperand(n int) bool {
+	if n == 0 {
+		return false
+	}
+	switch t := l.tokens[n-1]; t.ttype {
+	case tNUM, tIDENT, tRPAREN, tRVECPAR, tRLIST:
+		return true
+	case tKEYW:
+		return t.val == kwTRUE.name || t.val == kwFALSE.name || t.val == kwANS.name
+	case tABS:
+		// bar after operand closes abs
+		return l.afterOperand(n - 1)
+	}
+	return false
+}
+
+// closeAbs leaves brackets, bars opened in them stay unmatched
+func (l *Lexer) closeAbs() {
+	if n := len(l.outerAbs); n > 0 {
+		l.outerAbs, l.abs = l.outerAbs[:n-1], l.outerAbs[n-1]
+	}
+}
+
+// addToken appends token starting at l.start
+func (l *Lexer) addToken(tt TokenType, val string) {
+	l.tokens = append(l.tokens, Token{ttype: tt, val: val, pos: l.start})
+}
+
+// numChars returns chars of numbers
+func (l *Lexer) numChars() string {
+	if l.comma {
+		return sDIGITS + ".,"
+	}
+	return sDIGITS + "."
+}
+
+// makeNum lexes number like 1.5 or 1_000, or whole number with base prefix like 0x1F
+func (l *Lexer) makeNum() {
+	chars := l.numChars() + "_"
+	if next := l.pos + l.width; l.char == '0' && next < len(l.text) && strings.IndexByte("xXbBoO", l.text[next]) >= 0 {
+		// letters stay in number so 0b12 or 0xG is reported as a whole
+		chars = sLETTERS + sDIGITS + "_"
+	}
 	var numStr string
-	var dotCount int
-	for strings.ContainsRune(sDIGITS+".,", l.char) {
-		if l.char == '.' {
-			dotCount++
-		} else if l.char == ',' {
-			dotCount++
+	for strings.ContainsRune(chars, l.char) {
+		if l.char == ',' {
 			l.char = '.'
 		}
 		numStr += string(l.char)
 		l.advance()
 	}
-	if dotCount > 1 {
-		return SyntaxErr{numStr + " is not a number"}
+	if _, err := parseNum(numStr); err != nil {
+		l.bad(newErr(ErrNumber, numStr).at(l.start, l.pos))
+		return
 	}
-	l.tokens = append(l.tokens, Token{tNUM, numStr})
-	return nil
+	l.addToken(tNUM, numStr)
 }
 
 func (l *Lexer) makeIdentKwFunc() {
@@ -68,21 +130,21 @@ func (l *Lexer) makeIdentKwFunc() {
 		if identStr == kwVEC.name {
 			l.inVec = true
 		}
-		l.tokens = append(l.tokens, Token{tKEYW, identStr})
+		l.addToken(tKEYW, identStr)
 	} else if isFunc(identStr) {
-		l.tokens = append(l.tokens, Token{tFUNC, identStr})
+		l.addToken(tFUNC, identStr)
 	} else {
-		l.tokens = append(l.tokens, Token{tIDENT, identStr})
+		l.addToken(tIDENT, identStr)
 	}
 }
 
-// GenerateTokens generates token slice from text
+// GenerateTokens generates token slice from text, unreadable input becomes tBAD
+// tokens and errors of all of it are returned
 func (l *Lexer) GenerateTokens() ([]Token, error) {
 	for l.pos < len(l.text) {
-		if strings.ContainsRune(sDIGITS+".,", l.char) {
-			if err := l.makeNum(); err != nil {
-				return nil, err
-			}
+		l.start = l.pos
+		if strings.ContainsRune(l.numChars(), l.char) {
+			l.makeNum()
 			continue
 		}
 		if strings.ContainsRune(sLETTERS+"_", l.char) {
@@ -92,29 +154,83 @@ func (l *Lexer) GenerateTokens() ([]Token, error) {
 		switch l.char {
 		case ' ':
 			if l.inVec && l.paranDepth == 1 {
-				l.tokens = append(l.tokens, Token{tSPACE, string(l.char)})
+				l.addToken(tSPACE, string(l.char))
 			}
 		case '+':
-			l.tokens = append(l.tokens, Token{tPLUS, string(l.char)})
+			l.addToken(tPLUS, string(l.char))
 		case '-':
-			l.tokens = append(l.tokens, Token{tMINUS, string(l.char)})
+			if l.peekIs('>') {
+				l.advance()
+				l.addToken(tARROW, "->")
+				break
+			}
+			l.addToken(tMINUS, string(l.char))
 		case '*':
-			l.tokens = append(l.tokens, Token{tMUL, string(l.char)})
+			l.addToken(tMUL, string(l.char))
 		case '/', ':':
-			l.tokens = append(l.tokens, Token{tDIV, string(l.char)})
+			if l.char == ':' && l.peekIs('=') {
+				l.advance()
+				l.addToken(tDEFINE, ":=")
+				break
+			}
+			l.addToken(tDIV, string(l.char))
 		case '^':
-			l.tokens = append(l.tokens, Token{tPOW, string(l.char)})
+			l.addToken(tPOW, string(l.char))
 		case '\\':
-			l.tokens = append(l.tokens, Token{tROOT, string(l.char)})
+			l.addToken(tROOT, string(l.char))
 		case '=':
-			l.tokens = append(l.tokens, Token{tEQ, string(l.char)})
+			if l.peekIs('=') {
+				l.advance()
+				l.addToken(tEQEQ, "==")
+				break
+			}
+			l.addToken(tEQ, string(l.char))
+		case '<':
+			if l.peekIs('=') {
+				l.advance()
+				l.addToken(tLE, "<=")
+				break
+			}
+			l.addToken(tLT, string(l.char))
+		case '>':
+			if l.peekIs('=') {
+				l.advance()
+				l.addToken(tGE, ">=")
+				break
+			}
+			l.addToken(tGT, string(l.char))
+		case '!':
+			if l.peekIs('=') {
+				l.advance()
+				l.addToken(tNE, "!=")
+				break
+			}
+			l.addToken(tNOT, string(l.char))
+		case '~':
+			if !l.peekIs('=') {
+				l.bad(newErr(ErrCharacter, string(l.char)).at(l.pos, l.pos+l.width))
+				break
+			}
+			l.advance()
+			l.addToken(tAPPROX, "~=")
+		case '≈':
+			l.addToken(tAPPROX, string(l.char))
+		case '&':
+			if !l.peekIs('&') {
+				l.bad(newErr(ErrCharacter, string(l.char)).at(l.pos, l.pos+l.width))
+				break
+			}
+			l.advance()
+			l.addToken(tAND, "&&")
 		case '(':
-			l.tokens = append(l.tokens, Token{tLPAREN, string(l.char)})
+			l.addToken(tLPAREN, string(l.char))
+			l.outerAbs, l.abs = append(l.outerAbs, l.abs), 0
 			if l.inVec {
 				l.paranDepth++
 			}
 		case ')':
-			l.tokens = append(l.tokens, Token{tRPAREN, string(l.char)})
+			l.addToken(tRPAREN, string(l.char))
+			l.closeAbs()
 			if l.inVec {
 				l.paranDepth--
 				if l.paranDepth == 0 {
@@ -122,23 +238,60 @@ func (l *Lexer) GenerateTokens() ([]Token, error) {
 				}
 			}
 		case '[':
-			l.tokens = append(l.tokens, Token{tLVECPAR, string(l.char)})
-			l.inVec = true
-			l.paranDepth = 1
+			l.addToken(tLVECPAR, string(l.char))
+			l.outerAbs, l.abs = append(l.outerAbs, l.abs), 0
+			// brackets in vec index its fields, after operand they index it
+			if l.inVec {
+				l.paranDepth++
+			} else if !l.afterOperand(len(l.tokens) - 1) {
+				l.inVec = true
+				l.paranDepth = 1
+			}
 		case ']':
-			l.tokens = append(l.tokens, Token{tRVECPAR, string(l.char)})
-			l.inVec = false
-			l.paranDepth = 0
+			l.addToken(tRVECPAR, string(l.char))
+			l.closeAbs()
+			l.paranDepth--
+			if l.paranDepth <= 0 {
+				l.inVec = false
+				l.paranDepth = 0
+			}
+		case '{':
+			l.addToken(tLLIST, string(l.char))
+			l.outerAbs, l.abs = append(l.outerAbs, l.abs), 0
+			// spaces in list items do not separate fields of vec
+			if l.inVec {
+				l.paranDepth++
+			}
+		case '}':
+			l.addToken(tRLIST, string(l.char))
+			l.closeAbs()
+			if l.inVec {
+				l.paranDepth--
+			}
 		case '?':
-			l.tokens = append(l.tokens, Token{tABSQ, string(l.char)})
+			l.addToken(tABSQ, string(l.char))
 		case '|':
-			l.tokens = append(l.tokens, Token{tABS, string(l.char)})
+			// bar after operand closes abs, || there is or unless it closes two abs
+			after := l.afterOperand(len(l.tokens))
+			if l.peekIs('|') && after && l.abs < 2 {
+				l.advance()
+				l.addToken(tOR, "||")
+				break
+			}
+			if after && l.abs > 0 {
+				l.abs--
+			} else {
+				l.abs++
+			}
+			l.addToken(tABS, string(l.char))
 		case ';':
-			l.tokens = append(l.tokens, Token{tDLM, string(l.char)})
+			l.addToken(tDLM, string(l.char))
+		case ',':
+			l.bad(newErr(ErrDecimalComma).at(l.pos, l.pos+1))
 		default:
-			return nil, CharacterErr{string(l.char)}
+			l.bad(newErr(ErrCharacter, string(l.char)).at(l.pos, l.pos+l.width))
 		}
 		l.advance()
 	}
-	return l.tokens, nil
+	return l.tokens, l.errs.err()
 }
@@ -0,0 +1,72 @@
+package vector
+
+import (
+	"context"
+	"testing"
+)
+
+func TestLexBars(t *testing.T) {
+	tests := []struct {
+		src  string
+		want []TokenType
+	}{
+		{"a || b", []TokenType{tIDENT, tOR, tIDENT}},
+		{"||x| - 1|", []TokenType{tABS, tABS, tIDENT, tABS, tMINUS, tNUM, tABS}},
+		{"|a| || b", []TokenType{tABS, tIDENT, tABS, tOR, tIDENT}},
+		{"(a) || true || b", []TokenType{tLPAREN, tIDENT, tRPAREN, tOR, tKEYW, tOR, tIDENT}},
+		{"1 + ||x| - 1|", []TokenType{tNUM, tPLUS, tABS, tABS, tIDENT, tABS, tMINUS, tNUM, tABS}},
+		{"|1 - |2||", []TokenType{tABS, tNUM, tMINUS, tABS, tNUM, tABS, tABS}},
+		{"||1|-|2||", []TokenType{tABS, tABS, tNUM, tABS, tMINUS, tABS, tNUM, tABS, tABS}},
+		{"|a| || |b|", []TokenType{tABS, tIDENT, tABS, tOR, tABS, tIDENT, tABS}},
+	}
+	for _, tt := range tests {
+		tokens, err := NewLexer(tt.src).GenerateTokens()
+		if err != nil {
+			t.Fatalf("GenerateTokens(%q): %v", tt.src, err)
+		}
+		var got []TokenType
+		for _, tok := range tokens {
+			got = append(got, tok.ttype)
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("GenerateTokens(%q) = %v, want %v", tt.src, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("GenerateTokens(%q) = %v, want %v", tt.src, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestNestedAbs(t *testing.T) {
+	tests := []struct {
+		src, want, format string
+	}{
+		{"||-3| - 5|", "2", "|(|-3| - 5)|"},
+		{"| |[3 4]| - 1|", "4", "|(|[3 4]| - 1)|"},
+		{"|-1| == 1 || false", "true", "|-1| == 1 || false"},
+		{"|1 - |2||", "1", "|(1 - |2|)|"},
+		{"||1|-|2||", "1", "|(|1| - |2|)|"},
+		{"|| -3| - |4||", "1", "|(|-3| - |4|)|"},
+	}
+	for _, tt := range tests {
+		n, err := Parse(tt.src)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.src, err)
+		}
+		if got := Format(n); got != tt.format {
+			t.Errorf("Format(%q) = %q, want %q", tt.src, got, tt.format)
+		}
+		again, err := Parse(Format(n))
+		if err != nil || Format(again) != tt.format {
+			t.Errorf("Parse(Format(%q)) = %v %v", tt.src, again, err)
+		}
+		res, err := NewSession().Execute(context.Background(), n)
+		if err != nil || Format(res) != tt.want {
+			t.Errorf("%q = %v %v, want %s", tt.src, res, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package vector
+
+import (
+	"fmt"
+	"math"
+)
+
+// ListNode holds numbers and vectors
+type ListNode struct {
+	items []Node
+}
+
+func (n ListNode) resolve(s *state) (Node, error) {
+	var node ListNode
+	for _, it := range n.items {
+		it, err := s.resolve(it)
+		if err != nil {
+			return nil, err
+		}
+		switch it.(type) {
+		case NumberNode, BoolNode, VecNode, ListNode:
+		default:
+			return nil, newErr(ErrStore, typeName(it), "list")
+		}
+		node.items = append(node.items, it)
+	}
+	return node, nil
+}
+
+func (n ListNode) String() string {
+	return defaultDisplay.show(n)
+}
+
+// IndexNode accesses item of list or field of vec, counting from 1
+type IndexNode struct {
+	node  Node
+	index Node
+}
+
+func (n IndexNode) resolve(s *state) (Node, error) {
+	node, err := s.resolve(n.node)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := s.resolve(n.index)
+	if err != nil {
+		return nil, err
+	}
+	i, ok := idx.(NumberNode)
+	if !ok || i != NumberNode(math.Trunc(float64(i))) {
+		return nil, newErr(ErrIndexWhole, Format(n))
+	}
+
+	var items []Node
+	switch node := node.(type) {
+	case ListNode:
+		items = node.items
+	case VecNode:
+		items = node.fields
+	default:
+		return nil, newErr(ErrIndex, typeName(node), Format(n))
+	}
+	if i < 1 || int(i) > len(items) {
+		return nil, newErr(ErrIndexRange, i, len(items))
+	}
+	return items[int(i)-1], nil
+}
+
+func (n IndexNode) String() string {
+	return fmt.Sprintf("(%s[%s])", n.node, n.index)
+}
+
+func init() {
+	functions["len"] = funcDef{arity: 1, call: listLen}
+	functions["sum"] = funcDef{arity: 1, call: listSum}
+	functions["map"] = funcDef{arity: 2, call: listMap}
+	functions["filter"] = funcDef{arity: 2, call: listFilter}
+	functions["reduce"] = funcDef{arity: -1, call: listReduce}
+}
+
+// itemsArg resolves arg into items of list or vec
+func itemsArg(s *state, name string, arg Node) (Node, []Node, error) {
+	n, err := s.resolve(arg)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch n := n.(type) {
+	case ListNode:
+		return n, n.items, nil
+	case VecNode:
+		return n, n.fields, nil
+	}
+	return nil, nil, newErr(ErrArgItems, name)
+}
+
+// lambdaArg resolves arg into lambda
+func lambdaArg(s *state, name string, arg Node) (LambdaNode, error) {
+	n, err := s.resolve(arg)
+	if err != nil {
+		return LambdaNode{}, err
+	}
+	if l, ok := n.(LambdaNode); ok {
+		return l, nil
+	}
+	return LambdaNode{}, newErr(ErrArgFunc, name)
+}
+
+// truthy reports if predicate result counts as true
+func truthy(n Node) bool {
+	switch n := n.(type) {
+	case BoolNode:
+		return bool(n)
+	case NumberNode:
+		return n != 0
+	}
+	return false
+}
+
+// sameKind returns items as vec if coll is vec and all items are numbers
+func sameKind(coll Node, items []Node) Node {
+	if _, ok := coll.(VecNode); ok {
+		for _, it := range items {
+			if _, ok := it.(NumberNode); !ok {
+				return ListNode{items}
+			}
+		}
+		return VecNode{items}
+	}
+	return ListNode{items}
+}
+
+func listLen(s *state, args []Node) (Node, error) {
+	_, items, err := itemsArg(s, "len", args[0])
+	return NumberNode(len(items)), err
+}
+
+func listSum(s *state, args []Node) (Node, error) {
+	_, items, err := itemsArg(s, "sum", args[0])
+	if err != nil {
+		return nil, err
+	}
+	var res Node = NumberNode(0)
+	for i, it := range items {
+		if i == 0 {
+			// lone item is not added to anything but must still be summable
+			switch it.(type) {
+			case NumberNode, VecNode:
+			default:
+				return nil, newErr(ErrType, Format(it))
+			}
+			res = it
+			continue
+		}
+		if res, err = s.resolve(OperationNode{res, Token{ttype: tPLUS, val: "+"}, it}); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func listMap(s *state, args []Node) (Node, error) {
+	coll, items, err := itemsArg(s, "map", args[0])
+	if err != nil {
+		return nil, err
+	}
+	fn, err := lambdaArg(s, "map", args[1])
+	if err != nil {
+		return nil, err
+	}
+	var res []Node
+	for _, it := range items {
+		r, err := fn.call(s, []Node{it})
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, r)
+	}
+	return sameKind(coll, res), nil
+}
+
+func listFilter(s *state, args []Node) (Node, error) {
+	coll, items, err := itemsArg(s, "filter", args[0])
+	if err != nil {
+		return nil, err
+	}
+	fn, err := lambdaArg(s, "filter", args[1])
+	if err != nil {
+		return nil, err
+	}
+	var res []Node
+	for _, it := range items {
+		r, err := fn.call(s, []Node{it})
+		if err != nil {
+			return nil, err
+		}
+		if truthy(r) {
+			res = append(res, it)
+		}
+	}
+	return sameKind(coll, res), nil
+}
+
+// listReduce folds items with (acc; x) -> expr, starting with init or first item
+func listReduce(s *state, args []Node) (Node, error) {
+	if len(args) != 2 && len(args) != 3 {
+		return nil, newErr(ErrReduceArgs)
+	}
+	_, items, err := itemsArg(s, "reduce", args[0])
+	if err != nil {
+		return nil, err
+	}
+	fn, err := lambdaArg(s, "reduce", args[len(args)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	var acc Node
+	if len(args) == 3 {
+		if acc, err = s.resolve(args[1]); err != nil {
+			return nil, err
+		}
+	} else if len(items) == 0 {
+		return nil, newErr(ErrReduceEmpty)
+	} else {
+		acc, items = items[0], items[1:]
+	}
+	for _, it := range items {
+		if acc, err = fn.call(s, []Node{acc, it}); err != nil {
+			return nil, err
+		}
+	}
+	return acc, nil
+}
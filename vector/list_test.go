@@ -0,0 +1,70 @@
+package vector
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestListFuncs(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"len({1; 2; 3})", "3"},
+		{"len([1 2])", "2"},
+		{"len({})", "0"},
+		{"sum({1; 2; 3})", "6"},
+		{"sum([1 2 3])", "6"},
+		{"sum({[1 2]; [3 4]})", "[4 6]"},
+		{"map({1; 2; 3}; x -> x * 2)", "{2; 4; 6}"},
+		{"map([1 2 3]; x -> x ^ 2)", "[1 4 9]"},
+		{"map({0; 1}; x -> sin(x) * 0)", "{0; 0}"},
+		{"filter({1; 2; 3; 4}; x -> x > 2)", "{3; 4}"},
+		{"filter([1 2 3 4]; x -> x < 3)", "[1 2]"},
+		{"reduce({1; 2; 3}; (a; b) -> a * b)", "6"},
+		{"reduce({1; 2; 3}; 10; (a; b) -> a + b)", "16"},
+		{"reduce({}; 0; (a; b) -> a + b)", "0"},
+		{"{1; 2; 3}[2]", "2"},
+		{"{{1; 2}; 3}[1][2]", "2"},
+		{"[4 5 6][3]", "6"},
+		{"[4 5 6][1 + 1]", "5"},
+		{"{1; 2; 3}[ 2 * 1 + 1 ]", "3"},
+		{"[[1 2][1 + 1] 3]", "[2 3]"},
+	}
+	for _, tt := range tests {
+		res, err := NewSession().Run(context.Background(), tt.src)
+		if err != nil {
+			t.Errorf("Run(%q): %v", tt.src, err)
+			continue
+		}
+		if got := Format(res); got != tt.want {
+			t.Errorf("Run(%q) = %s, want %s", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestListFuncErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want Code
+	}{
+		{"len(1)", ErrArgItems},
+		{"map(1; x -> x)", ErrArgItems},
+		{"map({1}; 2)", ErrArgFunc},
+		{"reduce({}; (a; b) -> a + b)", ErrReduceEmpty},
+		{"reduce({1})", ErrReduceArgs},
+		{"sum({true})", ErrType},
+		{"sum({true; true})", ErrType},
+		{"sum({{1}})", ErrType},
+		{"{1; 2}[3]", ErrIndexRange},
+		{"{1; 2}[1.5]", ErrIndexWhole},
+		{"1[1]", ErrIndex},
+	}
+	for _, tt := range tests {
+		_, err := NewSession().Run(context.Background(), tt.src)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("Run(%q) = %v, want %v", tt.src, err, tt.want)
+		}
+	}
+}
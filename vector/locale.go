@@ -0,0 +1,145 @@
+package vector
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// locale holds messages and number format of one language
+type locale struct {
+	name string
+	// decimal separates fraction of numbers, group separates thousands
+	decimal, group byte
+	codes          map[Code]codeInfo
+	hints          map[Code]string
+	categories     [len(catNames)]string
+	// words translates word args of messages like expression in Expected expression
+	words     map[word]string
+	one, many string
+	help      string
+}
+
+var english = &locale{
+	name:       "en",
+	decimal:    '.',
+	group:      ',',
+	codes:      codes,
+	hints:      hints,
+	categories: catNames,
+	one:        "argument",
+	many:       "arguments",
+	help:       helpText,
+}
+
+var locales = map[string]*locale{
+	english.name: english,
+	german.name:  german,
+}
+
+// SetLang sets language of messages and numbers of std, like the lang keyword
+func SetLang(name string) error {
+	return std.SetLang(name)
+}
+
+// SetLang sets language of messages and numbers, like the lang keyword
+func (sess *Session) SetLang(name string) error {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	return sess.setLang(name)
+}
+
+func (sess *Session) setLang(name string) error {
+	if name == "reset" {
+		name = english.name
+	}
+	l, ok := locales[name]
+	if !ok {
+		var names []string
+		for n := range locales {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return sess.lang.localize(newErr(ErrLang, name, strings.Join(names, ", ")))
+	}
+	sess.lang, sess.display.lang = l, l
+	return nil
+}
+
+// commaDecimal reports if lexer reads , as decimal separator
+func (l *locale) commaDecimal() bool {
+	return l.decimal == ','
+}
+
+// count returns n arguments in l
+func (l *locale) count(n int) string {
+	if n == 1 {
+		return "1 " + l.one
+	}
+	return strconv.Itoa(n) + " " + l.many
+}
+
+// category returns name of category of c in l
+func (l *locale) category(c Code) string {
+	cat := c.Category()
+	if cat < 0 || int(cat) >= len(l.categories) {
+		return cat.String()
+	}
+	return l.categories[cat]
+}
+
+// localize translates message and hint of err into l
+func (l *locale) localize(err error) error {
+	if list, ok := err.(ErrorList); ok && l != english {
+		res := make(ErrorList, len(list))
+		for i, e := range list {
+			res[i] = l.localize(e).(Error)
+		}
+		return res
+	}
+	e, ok := err.(Error)
+	if !ok || l == english {
+		return err
+	}
+	info, ok := l.codes[e.Code]
+	if !ok {
+		return err
+	}
+	args := make([]interface{}, len(e.args))
+	for i, a := range e.args {
+		switch a := a.(type) {
+		case arguments:
+			args[i] = l.count(int(a))
+		case word:
+			if w, ok := l.words[a]; ok {
+				args[i] = w
+			} else {
+				args[i] = string(a)
+			}
+		default:
+			args[i] = a
+		}
+	}
+	e.Msg = fmt.Sprintf(info.format, args...)
+	if hint, ok := l.hints[e.Code]; ok {
+		e.Hint = hint
+	}
+	return e
+}
+
+// number replaces separators of formatted number str with those of l
+func (l *locale) number(str string) string {
+	if l == nil || l == english {
+		return str
+	}
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '.':
+			return rune(l.decimal)
+		case ',':
+			return rune(l.group)
+		}
+		return r
+	}, str)
+}
@@ -0,0 +1,28 @@
+package vector
+
+import (
+	"context"
+	"testing"
+)
+
+func TestLocalize(t *testing.T) {
+	sess := NewSession()
+	if err := sess.SetLang("de"); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		src, want string
+	}{
+		{"1 / 0", "E101: Division durch Null"},
+		{"1 +", "E004: Ausdruck erwartet"},
+		{"f(1; 2", "E004: ; oder ) erwartet"},
+		{"expression + 1", "E301: expression ist nicht definiert"},
+		{"sin(1; 2)", "E401: sin erwartet 1 Argument"},
+	}
+	for _, tt := range tests {
+		_, err := sess.Run(context.Background(), tt.src)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%s = %v, want %s", tt.src, err, tt.want)
+		}
+	}
+}
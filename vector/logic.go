@@ -0,0 +1,122 @@
+package vector
+
+// BoolNode is result of comparisons
+type BoolNode bool
+
+func (n BoolNode) resolve(s *state) (Node, error) {
+	return n, nil
+}
+
+func (n BoolNode) String() string {
+	if n {
+		return kwTRUE.name
+	}
+	return kwFALSE.name
+}
+
+func init() {
+	functions["if"] = funcDef{arity: -1, lazy: true, call: ifFunc}
+}
+
+// boolOf resolves n which must be bool
+func boolOf(s *state, n Node, ctx Node) (bool, error) {
+	res, err := s.resolve(n)
+	if err != nil {
+		return false, err
+	}
+	b, ok := res.(BoolNode)
+	if !ok {
+		return false, newErr(ErrBool, typeName(res), Format(ctx))
+	}
+	return bool(b), nil
+}
+
+// logic resolves && and || and skips right side when left decides
+func (n OperationNode) logic(s *state) (Node, error) {
+	left, err := boolOf(s, n.left, n)
+	if err != nil {
+		return nil, err
+	}
+	if left == (n.op.ttype == tOR) {
+		return BoolNode(left), nil
+	}
+	right, err := boolOf(s, n.right, n)
+	return BoolNode(right), err
+}
+
+// compare compares resolved sides of n
+func (n OperationNode) compare() (Node, error) {
+	if n.op.ttype == tEQEQ || n.op.ttype == tNE {
+		eq, ok := equal(n.left, n.right)
+		if !ok {
+			return nil, newErr(ErrCompare, typeName(n.left), typeName(n.right), Format(n))
+		}
+		return BoolNode(eq == (n.op.ttype == tEQEQ)), nil
+	}
+
+	l, lok := n.left.(NumberNode)
+	r, rok := n.right.(NumberNode)
+	if !lok || !rok {
+		return nil, newErr(ErrOrder, typeName(n.left), typeName(n.right), Format(n))
+	}
+	switch n.op.ttype {
+	case tLT:
+		return BoolNode(l < r), nil
+	case tGT:
+		return BoolNode(l > r), nil
+	case tLE:
+		return BoolNode(l <= r), nil
+	}
+	return BoolNode(l >= r), nil
+}
+
+// equal compares resolved values of same type, ok is false for different types
+func equal(a, b Node) (eq bool, ok bool) {
+	switch a := a.(type) {
+	case NumberNode:
+		b, ok := b.(NumberNode)
+		return a == b, ok
+	case BoolNode:
+		b, ok := b.(BoolNode)
+		return a == b, ok
+	case VecNode:
+		b, ok := b.(VecNode)
+		return ok && equalAll(a.fields, b.fields), ok
+	case ListNode:
+		b, ok := b.(ListNode)
+		return ok && equalAll(a.items, b.items), ok
+	}
+	return false, false
+}
+
+func equalAll(a, b []Node) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if eq, _ := equal(a[i], b[i]); !eq {
+			return false
+		}
+	}
+	return true
+}
+
+// ifFunc resolves if(cond; a; cond2; b; ...; else) and only resolves taken branch
+func ifFunc(s *state, args []Node) (Node, error) {
+	if len(args) < 2 {
+		return nil, newErr(ErrIfArgs)
+	}
+	for i := 0; i+1 < len(args); i += 2 {
+		ok, err := boolOf(s, args[i], FuncNode{fun: "if", args: args})
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return s.resolve(args[i+1])
+		}
+	}
+	if len(args)%2 == 1 {
+		return s.resolve(args[len(args)-1])
+	}
+	return nil, newErr(ErrIfNone)
+}
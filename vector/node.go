@@ -1,16 +1,14 @@
 package vector
 
 import (
-	"errors"
 	"fmt"
 	"math"
-	"strconv"
+	"strings"
 )
 
 // Node is node type
 type Node interface {
-	resolve() (Node, error)
-	fixVarRecursion(caller VarNode) Node
+	resolve(s *state) (Node, error)
 	String() string
 }
 
@@ -31,7 +29,7 @@ func (n NumberNode) mul(a NumberNode) NumberNode {
 
 func (n NumberNode) div(a NumberNode) (NumberNode, error) {
 	if a == 0 {
-		return 0, RuntimeErr{"Division by zero"}
+		return 0, newErr(ErrDivisionByZero)
 	}
 	return n / a, nil
 }
@@ -42,23 +40,20 @@ func (n NumberNode) pow(a NumberNode) NumberNode {
 
 func (n NumberNode) rot(a NumberNode) (NumberNode, error) {
 	if n < 0 {
-		return 0, RuntimeErr{"Negative number in root"}
+		return 0, newErr(ErrNegativeRoot)
 	} else if n == 0 {
 		return 0, nil
 	}
 	return NumberNode(math.Pow(float64(n), 1/float64(a))), nil
 }
 
-func (n NumberNode) resolve() (Node, error) {
+func (n NumberNode) resolve(s *state) (Node, error) {
 	return n, nil
 }
 
-func (n NumberNode) fixVarRecursion(caller VarNode) Node {
-	return n
-}
-
+// String formats n with default settings, Session.Show applies settings of session
 func (n NumberNode) String() string {
-	return strconv.FormatFloat(float64(n), 'f', -1, 64)
+	return defaultDisplay.num(float64(n))
 }
 
 // UnaryNode is node with one Token
@@ -67,25 +62,25 @@ type UnaryNode struct {
 	node Node
 }
 
-func (n UnaryNode) resolve() (Node, error) {
+func (n UnaryNode) resolve(s *state) (Node, error) {
 	var err error
-	n.node, err = n.node.resolve()
+	n.node, err = s.resolve(n.node)
 	if err != nil {
 		return nil, err
 	}
 	if n.node == nil {
-		return nil, errors.New("Invalid syntax -> what error?")
+		return nil, newErr(ErrNoOperand, n.op.val)
 	}
 
 	switch n.op.ttype {
 	case tPLUS:
-		return n.node.resolve()
+		return s.resolve(n.node)
 	case tMINUS:
 		switch n.node.(type) {
 		case NumberNode:
 			return -n.node.(NumberNode), nil
 		case VecNode:
-			n.node, err = n.node.resolve()
+			n.node, err = s.resolve(n.node)
 			if err != nil {
 				return nil, err
 			}
@@ -98,22 +93,15 @@ func (n UnaryNode) resolve() (Node, error) {
 		case VecNode:
 			return n.node.(VecNode).abs(), nil
 		}
+	case tNOT:
+		if b, ok := n.node.(BoolNode); ok {
+			return !b, nil
+		}
+		return nil, newErr(ErrBool, typeName(n.node), Format(n))
 	default:
-		return nil, ImplementErr{"Unary operator not implemented: " + n.op.String()}
-	}
-	return n, nil
-}
-
-func (n UnaryNode) fixVarRecursion(caller VarNode) Node {
-	nn, _ := n.node.resolve()
-	switch nn.(type) {
-	case VecNode:
-		return n
-	}
-	if n.node.fixVarRecursion(caller) != n.node {
-		n.node, _ = n.resolve()
+		return nil, newErr(ErrOperator, n.op.val)
 	}
-	return n
+	return nil, newErr(ErrType, Format(n))
 }
 
 func (n UnaryNode) String() string {
@@ -130,36 +118,43 @@ type OperationNode struct {
 func (n OperationNode) conflicts() bool {
 	switch n.left.(type) {
 	case VecNode:
-		switch n.right.(type) {
-		case NumberNode:
-			return true
-		}
+		_, ok := n.right.(VecNode)
+		return !ok
 	case NumberNode:
-		switch n.right.(type) {
-		case VecNode:
-			return true
-		}
+		_, ok := n.right.(NumberNode)
+		return !ok
 	}
 	return false
 }
 
-func (n OperationNode) resolve() (Node, error) {
+func (n OperationNode) resolve(s *state) (Node, error) {
 	var node Node
 	var err error
-	n.left, err = n.left.resolve()
+	if n.op.ttype == tAND || n.op.ttype == tOR {
+		return n.logic(s)
+	}
+	n.left, err = s.resolve(n.left)
 	if err != nil {
 		return nil, err
 	}
 
-	n.right, err = n.right.resolve()
+	n.right, err = s.resolve(n.right)
 	if err != nil {
 		return nil, err
 	}
 
 	switch n.op.ttype {
+	case tLT, tGT, tLE, tGE, tEQEQ, tNE:
+		return n.compare()
+	case tAPPROX:
+		eq, ok := s.sess.epsilon.equal(n.left, n.right)
+		if !ok {
+			return nil, newErr(ErrCompare, typeName(n.left), typeName(n.right), Format(n))
+		}
+		return BoolNode(eq), nil
 	case tPLUS:
 		if n.conflicts() {
-			return nil, RuntimeErr{"Cannot add vec and num"}
+			return nil, newErr(ErrAdd, typeName(n.left), typeName(n.right), Format(n))
 		}
 		switch n.left.(type) {
 		case VecNode:
@@ -167,11 +162,11 @@ func (n OperationNode) resolve() (Node, error) {
 		case NumberNode:
 			node = n.left.(NumberNode).add(n.right.(NumberNode))
 		default:
-			return nil, RuntimeErr{"Unexpected type"}
+			return nil, newErr(ErrType, Format(n))
 		}
 	case tMINUS:
 		if n.conflicts() {
-			return nil, RuntimeErr{"Cannot subtract vec and num"}
+			return nil, newErr(ErrSubtract, typeName(n.left), typeName(n.right), Format(n))
 		}
 		switch n.left.(type) {
 		case VecNode:
@@ -179,7 +174,7 @@ func (n OperationNode) resolve() (Node, error) {
 		case NumberNode:
 			node = n.left.(NumberNode).min(n.right.(NumberNode))
 		default:
-			return nil, RuntimeErr{"Unexpected type"}
+			return nil, newErr(ErrType, Format(n))
 		}
 	case tMUL:
 		switch n.left.(type) {
@@ -190,7 +185,7 @@ func (n OperationNode) resolve() (Node, error) {
 			case VecNode:
 				node = n.left.(VecNode).mul(n.right.(VecNode))
 			default:
-				return nil, ImplementErr{"Not implemented"}
+				return nil, newErr(ErrNotImplemented, Format(n))
 			}
 		case NumberNode:
 			switch n.right.(type) {
@@ -200,7 +195,7 @@ func (n OperationNode) resolve() (Node, error) {
 				node = n.right.(VecNode).scalarMul(n.left.(NumberNode))
 			}
 		default:
-			return nil, RuntimeErr{"Unexpected type"}
+			return nil, newErr(ErrType, Format(n))
 		}
 	case tDIV:
 		switch n.left.(type) {
@@ -211,7 +206,7 @@ func (n OperationNode) resolve() (Node, error) {
 					return nil, err
 				}
 			default:
-				return nil, ImplementErr{"Not implemented"}
+				return nil, newErr(ErrNotImplemented, Format(n))
 			}
 		case NumberNode:
 			switch n.right.(type) {
@@ -220,19 +215,19 @@ func (n OperationNode) resolve() (Node, error) {
 					return nil, err
 				}
 			case VecNode:
-				return nil, RuntimeErr{"Cannot divide by Vec"}
+				return nil, newErr(ErrDivideByVec, Format(n))
 			}
 		default:
-			return nil, RuntimeErr{"Unexpected type"}
+			return nil, newErr(ErrType, Format(n))
 		}
 	case tPOW:
 		switch n.left.(type) {
 		case VecNode:
-			return nil, ImplementErr{"Pow for vec not implemented"}
+			return nil, newErr(ErrVecPower, Format(n))
 		case NumberNode:
 			switch n.right.(type) {
 			case VecNode:
-				return nil, ImplementErr{"Pow for vec not implemented"}
+				return nil, newErr(ErrVecPower, Format(n))
 			case NumberNode:
 				node = n.left.(NumberNode).pow(n.right.(NumberNode))
 			}
@@ -240,11 +235,11 @@ func (n OperationNode) resolve() (Node, error) {
 	case tROOT:
 		switch n.left.(type) {
 		case VecNode:
-			return nil, ImplementErr{"Pow for vec not implemented"}
+			return nil, newErr(ErrVecPower, Format(n))
 		case NumberNode:
 			switch n.right.(type) {
 			case VecNode:
-				return nil, ImplementErr{"Pow for vec not implemented"}
+				return nil, newErr(ErrVecPower, Format(n))
 			case NumberNode:
 				if node, err = n.right.(NumberNode).rot(n.left.(NumberNode)); err != nil {
 					return nil, err
@@ -252,69 +247,79 @@ func (n OperationNode) resolve() (Node, error) {
 			}
 		}
 	}
-	return node, nil
-}
-
-func (n OperationNode) fixVarRecursion(caller VarNode) Node {
-	nn, _ := n.left.resolve()
-	switch nn.(type) {
-	case VecNode:
-		return n
-	}
-	if n.left.fixVarRecursion(caller) != n.left {
-		res, _ := n.resolve()
-		return res
-	} else if n.right.fixVarRecursion(caller) != n.right {
-		res, _ := n.resolve()
-		return res
+	if node == nil {
+		return nil, newErr(ErrType, Format(n))
 	}
-	return n
+	return node, nil
 }
 
 func (n OperationNode) String() string {
 	return fmt.Sprintf("(%s %s %s)", n.left, n.op.val, n.right)
 }
 
-// VarNode holds Ident and value
+// VarNode holds Ident and value, eager assignments store value instead of formula
 type VarNode struct {
 	ident Token
 	val   Node
+	eager bool
 }
 
-func (n VarNode) resolve() (Node, error) {
+func (n VarNode) resolve(s *state) (Node, error) {
+	name := n.ident.val
 	// var called
 	if n.val == nil {
-		if v, ok := memory[n.ident.val]; ok {
-			return v.resolve()
+		for i := len(s.scopes) - 1; i >= 0; i-- {
+			if v, ok := s.scopes[i][name]; ok {
+				return v, nil
+			}
+		}
+		if v, ok := constants[name]; ok {
+			return v, nil
+		}
+		if v, ok := s.sess.cache[name]; ok {
+			return v, nil
 		}
-		return nil, RuntimeErr{n.ident.val + " is not defined"}
+		if v, ok := s.sess.memory[name]; ok {
+			// stored formulas never see parameters of the caller
+			scopes := s.scopes
+			s.scopes = nil
+			s.foreign++
+			res, err := s.resolve(v)
+			s.foreign--
+			s.scopes = scopes
+			if err == nil {
+				s.sess.cache[name] = res
+			}
+			return res, err
+		}
+		return nil, newErr(ErrUndefined, name)
 	}
 
-	// test value for error
-	if _, err := n.val.resolve(); err != nil {
-		return nil, err
+	if _, ok := constants[name]; ok {
+		return nil, newErr(ErrConstant, name)
 	}
 
-	// test for recursion
-	n.val = n.val.fixVarRecursion(n)
-	memory[n.ident.val] = n.val
-
-	return nil, nil
-}
+	if n.eager {
+		val, err := s.resolve(n.val)
+		if err != nil {
+			return nil, err
+		}
+		s.sess.store(name, val, nil)
+		return nil, nil
+	}
 
-func (n VarNode) fixVarRecursion(caller VarNode) Node {
-	if n.ident == caller.ident {
-		tmp, _ := n.resolve()
-		return tmp
+	deps := refs(n.val)
+	if c := s.sess.graph.cycle(name, deps); c != nil {
+		return nil, newErr(ErrCycle, strings.Join(c, " -> "))
 	}
 
-	val := memory[n.ident.val] // .fixVarRecursion(caller)
-	switch val.(type) {
-	case VarNode:
-		val = val.fixVarRecursion(caller)
-		return val
+	// test value for error
+	if _, err := s.resolve(n.val); err != nil {
+		return nil, err
 	}
-	return n
+	s.sess.store(name, n.val, deps)
+
+	return nil, nil
 }
 
 func (n VarNode) String() string {
@@ -324,23 +329,23 @@ func (n VarNode) String() string {
 	return fmt.Sprintf("(%s::%s)", n.ident, n.val)
 }
 
-// FuncNode is func node
+// FuncNode is func node, pos is offset of its name in input
 type FuncNode struct {
 	fun  function
 	args []Node
-	ret  Node
-}
-
-func (n FuncNode) resolve() (Node, error) {
-	return nil, nil
+	pos  int
 }
 
-func (n FuncNode) fixVarRecursion(caller VarNode) Node {
-	return nil
+func (n FuncNode) resolve(s *state) (Node, error) {
+	def, ok := lookupFunc(n.fun)
+	if !ok {
+		return nil, newErr(ErrUndefined, string(n.fun))
+	}
+	return def.apply(s, n.fun, n.args)
 }
 
 func (n FuncNode) String() string {
-	return fmt.Sprintf("func:%s(%v):%s", n.fun, n.args, n.ret)
+	return fmt.Sprintf("func:%s(%v)", n.fun, n.args)
 }
 
 // VecNode represents Vector
@@ -435,7 +440,7 @@ func (n VecNode) scalarDiv(a NumberNode) (VecNode, error) {
 	var err error
 	var node VecNode
 	if a == 0 {
-		return VecNode{}, RuntimeErr{"Division by zero"}
+		return VecNode{}, newErr(ErrDivisionByZero)
 	}
 	for _, f := range n.fields {
 		f, err = f.(NumberNode).div(a)
@@ -456,35 +461,39 @@ func (n VecNode) abs() NumberNode {
 	return res
 }
 
-func (n VecNode) resolve() (Node, error) {
+func (n VecNode) resolve(s *state) (Node, error) {
 	var node VecNode
 	for _, f := range n.fields {
 		var err error
-		f, err = f.resolve()
+		f, err = s.resolve(f)
 		if err != nil {
 			return nil, err
 		}
 		switch f.(type) {
+		case NumberNode:
 		case VecNode:
-			return nil, RuntimeErr{"Vec in vec not allowed"}
+			return nil, newErr(ErrVecInVec)
+		default:
+			return nil, newErr(ErrStore, typeName(f), "vec")
 		}
 		node.fields = append(node.fields, f)
 	}
 	return node, nil
 }
 
-func (n VecNode) fixVarRecursion(caller VarNode) Node {
-	return n
+func (n VecNode) String() string {
+	return defaultDisplay.show(n)
 }
 
-func (n VecNode) String() string {
-	var str = "vec("
-	for i, nd := range n.fields {
-		if i == len(n.fields)-1 {
-			str += fmt.Sprintf("%s", nd)
-			continue
-		}
-		str += fmt.Sprintf("%s ", nd)
-	}
-	return str + ")"
+// BadNode stands for input parser skipped after error, it keeps partial trees whole
+type BadNode struct {
+	span Span
+}
+
+func (n BadNode) resolve(s *state) (Node, error) {
+	return nil, newErr(ErrExpected, word("expression")).at(n.span.Start, n.span.End)
+}
+
+func (n BadNode) String() string {
+	return "BAD"
 }
@@ -1,15 +1,12 @@
 package vector
 
-import (
-	"log"
-	"strconv"
-)
-
 // Parser is type Parser
 type Parser struct {
 	tokens []Token
 	pos    int
 	curTok Token
+	// errs are errors recovered from, parsing went on after them
+	errs ErrorList
 }
 
 // NewParser returns new Parser
@@ -42,6 +39,144 @@ func (p *Parser) peek() Token {
 	return p.tokens[p.pos+1]
 }
 
+// fail returns error of code at current token
+func (p *Parser) fail(code Code, args ...interface{}) Error {
+	err := newErr(code, args...)
+	if p.curTok.ttype == tEMPTY {
+		return err
+	}
+	return err.at(p.curTok.pos, p.curTok.pos+len(p.curTok.val))
+}
+
+// expected reports missing what, at end of input more lines may follow
+func (p *Parser) expected(what word) error {
+	if p.curTok.ttype == tEMPTY {
+		return newErr(ErrIncomplete, what)
+	}
+	return p.fail(ErrExpected, what)
+}
+
+// report keeps err recovered from, lexer reported errors at bad tokens already
+func (p *Parser) report(err error) {
+	if p.curTok.ttype == tBAD {
+		return
+	}
+	switch err := err.(type) {
+	case Error:
+		p.errs = append(p.errs, err)
+	case ErrorList:
+		p.errs = append(p.errs, err...)
+	default:
+		p.errs = append(p.errs, newErr(ErrExpected, word("expression")))
+	}
+}
+
+// skip recovers from err by skipping tokens up to one of stops outside of brackets,
+// skipped input becomes BadNode. At end of input nothing is left to recover with
+func (p *Parser) skip(err error, stops ...TokenType) (Node, error) {
+	if p.curTok.ttype == tEMPTY {
+		return nil, err
+	}
+	p.report(err)
+	bad := BadNode{Span{p.curTok.pos, p.curTok.pos}}
+	var depth int
+	for p.curTok.ttype != tEMPTY {
+		if depth == 0 && hasType(stops, p.curTok.ttype) {
+			break
+		}
+		switch p.curTok.ttype {
+		case tLPAREN, tLVECPAR, tLLIST:
+			depth++
+		case tRPAREN, tRVECPAR, tRLIST:
+			if depth > 0 {
+				depth--
+			}
+		}
+		bad.span.End = p.curTok.pos + len(p.curTok.val)
+		p.advance()
+	}
+	return bad, nil
+}
+
+func hasType(types []TokenType, tt TokenType) bool {
+	for _, t := range types {
+		if t == tt {
+			return true
+		}
+	}
+	return false
+}
+
+// or parses lowest level of expressions, a || b
+func (p *Parser) or() (Node, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.curTok.ttype == tOR {
+		node := OperationNode{left: left, op: p.curTok}
+		p.advance()
+		if node.right, err = p.and(); err != nil {
+			return nil, err
+		}
+		left = node
+	}
+	return left, nil
+}
+
+func (p *Parser) and() (Node, error) {
+	left, err := p.not()
+	if err != nil {
+		return nil, err
+	}
+	for p.curTok.ttype == tAND {
+		node := OperationNode{left: left, op: p.curTok}
+		p.advance()
+		if node.right, err = p.not(); err != nil {
+			return nil, err
+		}
+		left = node
+	}
+	return left, nil
+}
+
+func (p *Parser) not() (Node, error) {
+	if p.curTok.ttype != tNOT {
+		return p.compare()
+	}
+	node := UnaryNode{op: p.curTok}
+	p.advance()
+	var err error
+	node.node, err = p.not()
+	return node, err
+}
+
+// isCompare reports if tt compares two values
+func isCompare(tt TokenType) bool {
+	switch tt {
+	case tLT, tGT, tLE, tGE, tEQEQ, tNE, tAPPROX:
+		return true
+	}
+	return false
+}
+
+// compare parses a < b, comparisons do not chain
+func (p *Parser) compare() (Node, error) {
+	left, err := p.expr()
+	if err != nil || !isCompare(p.curTok.ttype) {
+		return left, err
+	}
+	node := OperationNode{left: left, op: p.curTok}
+	p.advance()
+	if node.right, err = p.expr(); err != nil {
+		return nil, err
+	}
+	if isCompare(p.curTok.ttype) {
+		return nil, p.fail(ErrChained)
+	}
+	return node, nil
+}
+
 func (p *Parser) expr() (Node, error) {
 	left, err := p.term()
 	if err != nil {
@@ -106,12 +241,13 @@ func (p *Parser) makeUnaryNode() (UnaryNode, error) {
 	if p.curTok.ttype == tABS {
 		node.op = Token{ttype: tABSQ, val: "?"}
 		p.advance()
-		node.node, err = p.expr()
-		if err != nil {
-			return node, err
+		if node.node, err = p.expr(); err != nil {
+			if node.node, err = p.skip(err, tABS); err != nil {
+				return node, err
+			}
 		}
 		if p.curTok.ttype != tABS {
-			return node, SyntaxErr{"Expected |"}
+			return node, p.expected("|")
 		}
 		p.advance()
 		return node, nil
@@ -127,10 +263,11 @@ func (p *Parser) makeParens() (Node, error) {
 	var node Node
 	var err error
 	p.advance()
-	// log.Println(p.pos)
-	node, err = p.expr()
-	if p.curTok.ttype != tRPAREN {
-		err = SyntaxErr{"Expected )"}
+	if node, err = p.or(); err != nil {
+		node, err = p.skip(err, tRPAREN)
+	}
+	if err == nil && p.curTok.ttype != tRPAREN {
+		err = p.expected(")")
 	}
 	p.advance()
 	return node, err
@@ -141,29 +278,34 @@ func (p *Parser) makeVarNode() (VarNode, error) {
 	var err error
 	node.ident = p.curTok
 	p.advance()
-	if p.curTok.ttype != tEQ {
+	if p.curTok.ttype != tEQ && p.curTok.ttype != tDEFINE {
 		return node, nil
 	}
+	node.eager = p.curTok.ttype == tDEFINE
 	p.advance()
-	node.val, err = p.expr()
+	node.val, err = p.or()
 	switch node.val.(type) {
 	case VarNode:
-		if node.val.(VarNode).val != nil {
-			return node, SyntaxErr{"Cannot assign variable in variable assignment"}
+		if inner := node.val.(VarNode); inner.val != nil {
+			return node, newErr(ErrNestedAssign).at(inner.ident.pos, inner.ident.pos+len(inner.ident.val))
 		}
 	}
 	return node, err
 }
 
 func (p *Parser) makeNumNode() (NumberNode, error) {
-	f, err := strconv.ParseFloat(p.curTok.val, 64)
+	f, err := parseNum(p.curTok.val)
+	if err != nil {
+		return 0, p.fail(ErrNumber, p.curTok.val)
+	}
 	p.advance()
-	return NumberNode(f), err
+	return NumberNode(f), nil
 }
 
 func (p *Parser) makeAns() Node {
+	ident := Token{ttype: tIDENT, val: kwANS.name, pos: p.curTok.pos}
 	p.advance()
-	return VarNode{Token{ttype: tIDENT, val: "ans"}, nil}
+	return VarNode{ident: ident}
 }
 
 func (p *Parser) makeKeywNode() (Node, error) {
@@ -173,42 +315,150 @@ func (p *Parser) makeKeywNode() (Node, error) {
 	case kwVEC.name:
 		node, err = p.makeVecNode()
 	case kwQUIT.name, kwQUIT.getNameByAlias(p.curTok.val):
-		err = ExitErr{}
+		node = p.makeCmdNode(kwQUIT)
 	case kwHELP.name:
-		err = HelpErr{}
+		node = p.makeCmdNode(kwHELP)
 	case kwANS.name:
 		node = p.makeAns()
 	case kwCLEAR.name, kwCLEAR.getNameByAlias(p.curTok.val):
-		err = ClearErr{}
+		node = p.makeCmdNode(kwCLEAR)
+	case kwEXPORT.name, kwEXPORT.getNameByAlias(p.curTok.val):
+		node = p.makeCmdNode(kwEXPORT)
+	case kwHISTORY.name:
+		node = p.makeCmdNode(kwHISTORY)
+	case kwFORMAT.name:
+		node = p.makeArgsCmdNode(kwFORMAT)
+	case kwEPS.name:
+		node = p.makeArgsCmdNode(kwEPS)
+	case kwEXPLAIN.name:
+		node = p.makeArgsCmdNode(kwEXPLAIN)
+	case kwLANG.name:
+		node = p.makeArgsCmdNode(kwLANG)
+	case kwASSERT.name:
+		p.advance()
+		var expr Node
+		expr, err = p.or()
+		node = AssertNode{expr}
+	case kwTRACE.name:
+		p.advance()
+		var expr Node
+		expr, err = p.or()
+		node = TraceNode{expr}
+	case kwAST.name:
+		p.advance()
+		cmd := AstNode{format: "json"}
+		if (p.curTok.val == "dot" || p.curTok.val == "json") && p.peek().ttype != tEMPTY {
+			cmd.format = p.curTok.val
+			p.advance()
+		}
+		cmd.expr, err = p.or()
+		node = cmd
+	case kwDEPS.name:
+		node, err = p.makeNameCmdNode(kwDEPS)
+	case kwDEL.name, kwDEL.getNameByAlias(p.curTok.val):
+		node, err = p.makeNameCmdNode(kwDEL)
+	case kwSHOW.name:
+		node, err = p.makeNameCmdNode(kwSHOW)
+	case kwVARS.name:
+		node = p.makeCmdNode(kwVARS)
+	case kwRESET.name:
+		node = p.makeCmdNode(kwRESET)
+	case kwTRUE.name, kwFALSE.name:
+		node = BoolNode(p.curTok.val == kwTRUE.name)
+		p.advance()
 	default:
-		err = ImplementErr{"Keyword not implemented"}
+		err = p.fail(ErrKeyword)
 	}
 	return node, err
 }
 
+func (p *Parser) makeCmdNode(kw keyWord) CmdNode {
+	p.advance()
+	return CmdNode{kw: kw}
+}
+
+// makeArgsCmdNode makes command taking rest of input as args
+func (p *Parser) makeArgsCmdNode(kw keyWord) CmdNode {
+	cmd := p.makeCmdNode(kw)
+	for p.curTok.ttype != tEMPTY {
+		cmd.args = append(cmd.args, p.curTok)
+		p.advance()
+	}
+	return cmd
+}
+
+// makeNameCmdNode makes command taking variable name
+func (p *Parser) makeNameCmdNode(kw keyWord) (CmdNode, error) {
+	cmd := p.makeCmdNode(kw)
+	if p.curTok.ttype != tIDENT && p.curTok.val != kwANS.name {
+		return cmd, p.fail(ErrExpectedVar, kw.name)
+	}
+	cmd.args = append(cmd.args, p.curTok)
+	p.advance()
+	return cmd, nil
+}
+
 func (p *Parser) makeFuncNode() (FuncNode, error) {
-	return FuncNode{}, nil
+	node := FuncNode{fun: function(p.curTok.val), pos: p.curTok.pos}
+	p.advance()
+	if p.curTok.ttype != tLPAREN {
+		if p.curTok.ttype == tEMPTY {
+			return node, p.expected("(")
+		}
+		return node, p.fail(ErrExpectedParen, string(node.fun))
+	}
+	p.advance()
+	args, err := p.makeArgs()
+	node.args = args
+	return node, err
+}
+
+// makeArgs parses arguments up to closing paren, bad ones are skipped
+func (p *Parser) makeArgs() ([]Node, error) {
+	var args []Node
+	for p.curTok.ttype != tRPAREN {
+		if p.curTok.ttype == tEMPTY {
+			return args, p.expected(")")
+		}
+		arg, err := p.or()
+		if err != nil {
+			if arg, err = p.skip(err, tDLM, tRPAREN); err != nil {
+				return args, err
+			}
+		}
+		args = append(args, arg)
+		if p.curTok.ttype != tDLM && p.curTok.ttype != tRPAREN {
+			if _, err := p.skip(p.expected("; or )"), tDLM, tRPAREN); err != nil {
+				return args, err
+			}
+		}
+		if p.curTok.ttype == tDLM {
+			p.advance()
+		}
+	}
+	p.advance()
+	return args, nil
 }
 
 func (p *Parser) makeVecNode() (VecNode, error) {
 	var node VecNode
 
-	var startTok = Token{tLVECPAR, "["}
-	var endTok = Token{tRVECPAR, "]"}
+	var startTok = Token{ttype: tLVECPAR, val: "["}
+	var endTok = Token{ttype: tRVECPAR, val: "]"}
 	p.advance()
 	if p.previous().ttype != tLVECPAR {
 		if p.curTok.ttype != tLPAREN {
-			return node, SyntaxErr{"Expected ("}
+			return node, p.expected("(")
 		}
 		p.advance()
-		startTok = Token{tLPAREN, "("}
-		endTok = Token{tRPAREN, ")"}
+		startTok = Token{ttype: tLPAREN, val: "("}
+		endTok = Token{ttype: tRPAREN, val: ")"}
 	}
 
 	for p.curTok.ttype != endTok.ttype {
 		switch p.curTok.ttype {
 		case tEMPTY:
-			return node, SyntaxErr{"Expected " + endTok.val}
+			return node, p.expected(word(endTok.val))
 		case tSPACE:
 			for p.curTok.ttype == tSPACE {
 				p.advance()
@@ -237,14 +487,16 @@ func (p *Parser) makeVecNode() (VecNode, error) {
 
 		n, err := p.expr()
 		if err != nil {
-			return node, err
+			if n, err = p.skip(err, tSPACE, tDLM, endTok.ttype); err != nil {
+				return node, err
+			}
 		}
 		switch n.(type) {
 		case VecNode:
-			return node, SyntaxErr{"Vec in vec not allowed"}
+			p.report(p.fail(ErrVecInVec))
 		case VarNode:
 			if n.(VarNode).val != nil {
-				return node, SyntaxErr{"Cannot assign var in vec"}
+				p.report(p.fail(ErrAssignInVec))
 			}
 		}
 		node.fields = append(node.fields, n)
@@ -254,9 +506,105 @@ func (p *Parser) makeVecNode() (VecNode, error) {
 	return node, nil
 }
 
+func (p *Parser) makeListNode() (ListNode, error) {
+	var node ListNode
+	p.advance()
+	for p.curTok.ttype != tRLIST {
+		if p.curTok.ttype == tEMPTY {
+			return node, p.expected("}")
+		}
+		item, err := p.or()
+		if err != nil {
+			if item, err = p.skip(err, tDLM, tRLIST); err != nil {
+				return node, err
+			}
+		}
+		node.items = append(node.items, item)
+		if p.curTok.ttype != tDLM && p.curTok.ttype != tRLIST {
+			if _, err := p.skip(p.expected("; or }"), tDLM, tRLIST); err != nil {
+				return node, err
+			}
+		}
+		if p.curTok.ttype == tDLM {
+			p.advance()
+		}
+	}
+	p.advance()
+	return node, nil
+}
+
+func (p *Parser) makeIndexNode(node Node) (IndexNode, error) {
+	var err error
+	idx := IndexNode{node: node}
+	p.advance()
+	if idx.index, err = p.or(); err != nil {
+		if idx.index, err = p.skip(err, tRVECPAR); err != nil {
+			return idx, err
+		}
+	}
+	if p.curTok.ttype != tRVECPAR {
+		return idx, p.expected("]")
+	}
+	p.advance()
+	return idx, nil
+}
+
+func (p *Parser) makeCallNode(fn Node) (CallNode, error) {
+	node := CallNode{fn: fn}
+	p.advance()
+	args, err := p.makeArgs()
+	node.args = args
+	return node, err
+}
+
+// isLambda reports if parens at current token hold params followed by ->
+func (p *Parser) isLambda() bool {
+	i := p.pos + 1
+	for i < len(p.tokens) && p.tokens[i].ttype == tIDENT {
+		i++
+		if i < len(p.tokens) && p.tokens[i].ttype == tDLM {
+			i++
+		}
+	}
+	return i+1 < len(p.tokens) && p.tokens[i].ttype == tRPAREN && p.tokens[i+1].ttype == tARROW
+}
+
+func (p *Parser) makeLambdaNode() (LambdaNode, error) {
+	var node LambdaNode
+	var err error
+	if p.curTok.ttype == tIDENT {
+		node.params = append(node.params, p.curTok.val)
+		p.advance()
+	} else {
+		p.advance()
+		for p.curTok.ttype == tIDENT {
+			dup := false
+			for _, param := range node.params {
+				if param == p.curTok.val {
+					p.report(p.fail(ErrDuplicateParam, param))
+					dup = true
+				}
+			}
+			if !dup {
+				node.params = append(node.params, p.curTok.val)
+			}
+			p.advance()
+			if p.curTok.ttype == tDLM {
+				p.advance()
+			}
+		}
+		p.advance()
+	}
+	p.advance()
+	node.body, err = p.or()
+	return node, err
+}
+
 func (p *Parser) factor() (Node, error) {
 	var node Node
 	var err error
+	// parenthesized lambdas can be called like (x -> x * 2)(3)
+	var parens bool
 
 	switch p.curTok.ttype {
 	case tNUM:
@@ -264,30 +612,95 @@ func (p *Parser) factor() (Node, error) {
 	case tMINUS, tPLUS, tABSQ, tABS:
 		node, err = p.makeUnaryNode()
 	case tLPAREN:
-		node, err = p.makeParens()
+		if p.isLambda() {
+			node, err = p.makeLambdaNode()
+		} else {
+			node, err = p.makeParens()
+			parens = true
+		}
 	case tLVECPAR:
 		node, err = p.makeVecNode()
+	case tLLIST:
+		node, err = p.makeListNode()
 	case tIDENT:
-		node, err = p.makeVarNode()
+		if p.peek().ttype == tARROW {
+			node, err = p.makeLambdaNode()
+		} else {
+			node, err = p.makeVarNode()
+		}
 	case tKEYW:
 		node, err = p.makeKeywNode()
 	case tFUNC:
 		node, err = p.makeFuncNode()
+	case tBAD:
+		// lexer reported it already
+		node = BadNode{Span{p.curTok.pos, p.curTok.pos + len(p.curTok.val)}}
+		p.advance()
 	default:
-		log.Println(p.curTok)
-		err = SyntaxErr{"Expected expression"}
+		err = p.expected("expression")
+	}
+	for err == nil {
+		switch {
+		case p.curTok.ttype == tLVECPAR:
+			node, err = p.makeIndexNode(node)
+		case p.curTok.ttype == tLPAREN && (parens || callable(node)):
+			node, err = p.makeCallNode(node)
+		default:
+			return node, nil
+		}
 	}
 	return node, err
 }
 
-// Parse creates AST from tokens
+// callable reports if call syntax may follow n
+func callable(n Node) bool {
+	switch n := n.(type) {
+	case VarNode:
+		return n.val == nil
+	case CallNode, IndexNode:
+		return true
+	}
+	return false
+}
+
+// Parse creates AST from tokens. It reports all errors it recovers from as ErrorList,
+// then AST is partial with BadNode for input skipped. After error outside of
+// brackets parsing goes on behind next binary operator, so later errors show too
 func (p *Parser) Parse() (Node, error) {
-	node, err := p.expr()
+	node, err := p.or()
+	if err == nil && p.pos < len(p.tokens) {
+		err = p.expected("expression")
+	}
+	if err == nil {
+		return node, p.errs.err()
+	}
+	if node == nil {
+		node = BadNode{Span{0, p.end()}}
+	}
+	for err != nil && p.curTok.ttype != tEMPTY {
+		p.skip(err, binaryOps...)
+		if p.curTok.ttype == tEMPTY {
+			return node, p.errs.err()
+		}
+		p.advance()
+		if _, err = p.or(); err == nil && p.pos < len(p.tokens) {
+			err = p.expected("expression")
+		}
+	}
 	if err != nil {
-		return nil, err
+		p.report(err)
 	}
-	if p.pos != len(p.tokens) {
-		return nil, SyntaxErr{"Expected expression"}
+	return node, p.errs.err()
+}
+
+// binaryOps are operators parsing syncs at after error
+var binaryOps = []TokenType{tOR, tAND, tLT, tGT, tLE, tGE, tEQEQ, tNE, tAPPROX, tPLUS, tMINUS, tMUL, tDIV, tPOW, tROOT}
+
+// end returns end of last token
+func (p *Parser) end() int {
+	if len(p.tokens) == 0 {
+		return 0
 	}
-	return node, nil
+	last := p.tokens[len(p.tokens)-1]
+	return last.pos + len(last.val)
 }
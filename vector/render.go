@@ -0,0 +1,370 @@
+package vector
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	precASSIGN = iota
+	precOR
+	precAND
+	precNOT
+	precCOMPARE
+	precSUM
+	precPRODUCT
+	precPOWER
+	precUNARY
+	precATOM
+)
+
+// prec returns binding strength of node
+func prec(n Node) int {
+	switch n := n.(type) {
+	case NumberNode:
+		if n < 0 {
+			return precUNARY
+		}
+	case UnaryNode:
+		if n.op.ttype == tNOT {
+			return precNOT
+		} else if n.op.ttype != tABSQ {
+			return precUNARY
+		}
+	case OperationNode:
+		switch n.op.ttype {
+		case tOR:
+			return precOR
+		case tAND:
+			return precAND
+		case tLT, tGT, tLE, tGE, tEQEQ, tNE, tAPPROX:
+			return precCOMPARE
+		case tPLUS, tMINUS:
+			return precSUM
+		case tMUL, tDIV:
+			return precPRODUCT
+		case tPOW, tROOT:
+			return precPOWER
+		}
+	case VarNode:
+		if n.val != nil {
+			return precASSIGN
+		}
+	case LambdaNode:
+		return precASSIGN
+	}
+	return precATOM
+}
+
+// needsParens reports if side of n must be wrapped
+func needsParens(n OperationNode, side Node, right bool) bool {
+	p := prec(n)
+	switch n.op.ttype {
+	case tPOW:
+		return !right && prec(side) < precATOM
+	case tDIV, tROOT:
+		return false
+	case tLT, tGT, tLE, tGE, tEQEQ, tNE, tAPPROX:
+		return prec(side) <= p
+	}
+	if right {
+		if prec(side) == precUNARY {
+			return true
+		} else if n.op.ttype == tMINUS {
+			return prec(side) <= p
+		}
+	}
+	return prec(side) < p
+}
+
+var latexOps = map[TokenType]string{
+	tLT:     "<",
+	tGT:     ">",
+	tLE:     `\le`,
+	tGE:     `\ge`,
+	tEQEQ:   "=",
+	tNE:     `\ne`,
+	tAPPROX: `\approx`,
+	tAND:    `\land`,
+	tOR:     `\lor`,
+	tNOT:    `\lnot `,
+}
+
+var mathmlOps = map[TokenType]string{
+	tLT:     "&lt;",
+	tGT:     "&gt;",
+	tLE:     "&#x2264;",
+	tGE:     "&#x2265;",
+	tEQEQ:   "=",
+	tNE:     "&#x2260;",
+	tAPPROX: "&#x2248;",
+	tAND:    "&#x2227;",
+	tOR:     "&#x2228;",
+	tNOT:    "&#xAC;",
+}
+
+var latexFuncs = map[function]bool{
+	"sin": true,
+	"cos": true,
+	"tan": true,
+	"log": true,
+	"ln":  true,
+}
+
+// LaTeX renders node as LaTeX
+func LaTeX(n Node) string {
+	switch n := n.(type) {
+	case nil:
+		return ""
+	case NumberNode:
+		return latexNum(n.String())
+	case BoolNode:
+		return `\mathrm{` + n.String() + `}`
+	case VecNode:
+		var fields []string
+		for _, f := range n.fields {
+			fields = append(fields, LaTeX(f))
+		}
+		return `\begin{pmatrix} ` + strings.Join(fields, ` \\ `) + ` \end{pmatrix}`
+	case UnaryNode:
+		if n.op.ttype == tABSQ {
+			return `\left| ` + LaTeX(n.node) + ` \right|`
+		} else if n.op.ttype == tNOT {
+			if prec(n.node) < precATOM {
+				return latexOps[tNOT] + `\left(` + LaTeX(n.node) + `\right)`
+			}
+			return latexOps[tNOT] + LaTeX(n.node)
+		}
+		if prec(n.node) <= precUNARY {
+			return n.op.val + `\left(` + LaTeX(n.node) + `\right)`
+		}
+		return n.op.val + LaTeX(n.node)
+	case OperationNode:
+		left, right := LaTeX(n.left), LaTeX(n.right)
+		if needsParens(n, n.left, false) {
+			left = `\left(` + left + `\right)`
+		}
+		if needsParens(n, n.right, true) {
+			right = `\left(` + right + `\right)`
+		}
+		switch n.op.ttype {
+		case tMUL:
+			return left + ` \cdot ` + right
+		case tDIV:
+			return `\frac{` + left + `}{` + right + `}`
+		case tPOW:
+			return left + `^{` + right + `}`
+		case tROOT:
+			if n.left == NumberNode(2) {
+				return `\sqrt{` + right + `}`
+			}
+			return `\sqrt[` + left + `]{` + right + `}`
+		}
+		if op, ok := latexOps[n.op.ttype]; ok {
+			return left + " " + op + " " + right
+		}
+		return left + " " + n.op.val + " " + right
+	case VarNode:
+		name := latexIdent(n.ident.val)
+		if n.val == nil {
+			return name
+		}
+		if n.eager {
+			return name + " := " + LaTeX(n.val)
+		}
+		return name + " = " + LaTeX(n.val)
+	case FuncNode:
+		var args []string
+		for _, a := range n.args {
+			args = append(args, LaTeX(a))
+		}
+		name := `\operatorname{` + latexIdent(string(n.fun)) + `}`
+		if latexFuncs[n.fun] {
+			name = `\` + string(n.fun)
+		}
+		return name + `\left(` + strings.Join(args, ", ") + `\right)`
+	case CallNode:
+		var args []string
+		for _, a := range n.args {
+			args = append(args, LaTeX(a))
+		}
+		fn := LaTeX(n.fn)
+		if prec(n.fn) < precATOM {
+			fn = `\left(` + fn + `\right)`
+		}
+		return fn + `\left(` + strings.Join(args, ", ") + `\right)`
+	case ListNode:
+		var items []string
+		for _, it := range n.items {
+			items = append(items, LaTeX(it))
+		}
+		return `\left\{ ` + strings.Join(items, ", ") + ` \right\}`
+	case IndexNode:
+		node := LaTeX(n.node)
+		if prec(n.node) < precATOM {
+			node = `\left(` + node + `\right)`
+		}
+		return `{` + node + `}_{` + LaTeX(n.index) + `}`
+	case LambdaNode:
+		var params []string
+		for _, p := range n.params {
+			params = append(params, latexIdent(p))
+		}
+		if len(params) == 1 {
+			return params[0] + ` \mapsto ` + LaTeX(n.body)
+		}
+		return `\left(` + strings.Join(params, ", ") + `\right) \mapsto ` + LaTeX(n.body)
+	}
+	return n.String()
+}
+
+// latexNum renders fractions and exponents of displayed number
+func latexNum(str string) string {
+	if i := strings.IndexByte(str, '/'); i >= 0 {
+		if strings.HasPrefix(str, "-") {
+			return `-\frac{` + str[1:i] + `}{` + str[i+1:] + `}`
+		}
+		return `\frac{` + str[:i] + `}{` + str[i+1:] + `}`
+	}
+	if i := strings.IndexByte(str, 'e'); i >= 0 {
+		return str[:i] + ` \cdot 10^{` + str[i+1:] + `}`
+	}
+	return strings.ReplaceAll(str, ",", "{,}")
+}
+
+func latexIdent(name string) string {
+	name = strings.ReplaceAll(name, "_", `\_`)
+	if len(name) > 1 {
+		return `\mathrm{` + name + `}`
+	}
+	return name
+}
+
+// MathML renders node as MathML
+func MathML(n Node) string {
+	return `<math xmlns="http://www.w3.org/1998/Math/MathML">` + mathml(n) + `</math>`
+}
+
+func mathml(n Node) string {
+	switch n := n.(type) {
+	case nil:
+		return ""
+	case NumberNode:
+		if n < 0 {
+			return "<mrow><mo>-</mo>" + mathmlNum((-n).String()) + "</mrow>"
+		}
+		return mathmlNum(n.String())
+	case BoolNode:
+		return "<mi>" + n.String() + "</mi>"
+	case VecNode:
+		var rows string
+		for _, f := range n.fields {
+			rows += "<mtr><mtd>" + mathml(f) + "</mtd></mtr>"
+		}
+		return "<mrow><mo>(</mo><mtable>" + rows + "</mtable><mo>)</mo></mrow>"
+	case UnaryNode:
+		if n.op.ttype == tABSQ {
+			return "<mrow><mo>|</mo>" + mathml(n.node) + "<mo>|</mo></mrow>"
+		}
+		node := mathml(n.node)
+		if prec(n.node) <= precUNARY || n.op.ttype == tNOT && prec(n.node) < precATOM {
+			node = mathmlParens(node)
+		}
+		op := n.op.val
+		if n.op.ttype == tNOT {
+			op = mathmlOps[tNOT]
+		}
+		return "<mrow><mo>" + op + "</mo>" + node + "</mrow>"
+	case OperationNode:
+		left, right := mathml(n.left), mathml(n.right)
+		if needsParens(n, n.left, false) {
+			left = mathmlParens(left)
+		}
+		if needsParens(n, n.right, true) {
+			right = mathmlParens(right)
+		}
+		switch n.op.ttype {
+		case tMUL:
+			return "<mrow>" + left + "<mo>&#x22C5;</mo>" + right + "</mrow>"
+		case tDIV:
+			return "<mfrac>" + left + right + "</mfrac>"
+		case tPOW:
+			return "<msup>" + left + right + "</msup>"
+		case tROOT:
+			if n.left == NumberNode(2) {
+				return "<msqrt>" + right + "</msqrt>"
+			}
+			return "<mroot>" + right + left + "</mroot>"
+		}
+		op := n.op.val
+		if o, ok := mathmlOps[n.op.ttype]; ok {
+			op = o
+		}
+		return "<mrow>" + left + "<mo>" + op + "</mo>" + right + "</mrow>"
+	case VarNode:
+		name := "<mi>" + n.ident.val + "</mi>"
+		if n.val == nil {
+			return name
+		}
+		op := "="
+		if n.eager {
+			op = ":="
+		}
+		return "<mrow>" + name + "<mo>" + op + "</mo>" + mathml(n.val) + "</mrow>"
+	case FuncNode:
+		var args []string
+		for _, a := range n.args {
+			args = append(args, mathml(a))
+		}
+		return fmt.Sprintf("<mrow><mi>%s</mi><mo>&#x2061;</mo>%s</mrow>",
+			n.fun, mathmlParens(strings.Join(args, "<mo>,</mo>")))
+	case CallNode:
+		var args []string
+		for _, a := range n.args {
+			args = append(args, mathml(a))
+		}
+		fn := mathml(n.fn)
+		if prec(n.fn) < precATOM {
+			fn = mathmlParens(fn)
+		}
+		return "<mrow>" + fn + "<mo>&#x2061;</mo>" + mathmlParens(strings.Join(args, "<mo>,</mo>")) + "</mrow>"
+	case ListNode:
+		var items []string
+		for _, it := range n.items {
+			items = append(items, mathml(it))
+		}
+		return "<mrow><mo>{</mo>" + strings.Join(items, "<mo>,</mo>") + "<mo>}</mo></mrow>"
+	case IndexNode:
+		node := mathml(n.node)
+		if prec(n.node) < precATOM {
+			node = mathmlParens(node)
+		}
+		return "<msub>" + node + mathml(n.index) + "</msub>"
+	case LambdaNode:
+		var params []string
+		for _, p := range n.params {
+			params = append(params, "<mi>"+p+"</mi>")
+		}
+		head := strings.Join(params, "<mo>,</mo>")
+		if len(params) != 1 {
+			head = mathmlParens(head)
+		}
+		return "<mrow>" + head + "<mo>&#x21A6;</mo>" + mathml(n.body) + "</mrow>"
+	}
+	return "<mtext>" + n.String() + "</mtext>"
+}
+
+// mathmlNum renders fractions and exponents of displayed number
+func mathmlNum(str string) string {
+	if i := strings.IndexByte(str, '/'); i >= 0 {
+		return "<mfrac><mn>" + str[:i] + "</mn><mn>" + str[i+1:] + "</mn></mfrac>"
+	}
+	if i := strings.IndexByte(str, 'e'); i >= 0 {
+		return "<mrow><mn>" + str[:i] + "</mn><mo>&#xD7;</mo><msup><mn>10</mn><mn>" + str[i+1:] + "</mn></msup></mrow>"
+	}
+	return "<mn>" + str + "</mn>"
+}
+
+func mathmlParens(s string) string {
+	return "<mrow><mo>(</mo>" + s + "<mo>)</mo></mrow>"
+}
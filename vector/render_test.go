@@ -0,0 +1,59 @@
+package vector
+
+import "testing"
+
+func TestLaTeX(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"1 + 2 * 3", `1 + 2 \cdot 3`},
+		{"(1 + 2) * 3", `\left(1 + 2\right) \cdot 3`},
+		{"a - (b - c)", `a - \left(b - c\right)`},
+		{"(a - b) - c", `a - b - c`},
+		{"2 ^ (1 + 1)", `2^{1 + 1}`},
+		{"(2 ^ 3) ^ 2", `\left(2^{3}\right)^{2}`},
+		{"-(1 + 2)", `-\left(1 + 2\right)`},
+		{"|x - 1|", `\left| x - 1 \right|`},
+		{"(1 + 2) / 4", `\frac{1 + 2}{4}`},
+		{"sin(x + 1)", `\sin\left(x + 1\right)`},
+		{"[1 2 3]", `\begin{pmatrix} 1 \\ 2 \\ 3 \end{pmatrix}`},
+		{"{1; 2}", `\left\{ 1, 2 \right\}`},
+		{"x -> x ^ 2", `x \mapsto x^{2}`},
+		{"a < b && !c", `a < b \land \lnot c`},
+	}
+	for _, tt := range tests {
+		n, err := Parse(tt.in)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.in, err)
+		}
+		if got := LaTeX(n); got != tt.want {
+			t.Errorf("LaTeX(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMathML(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"1 + 2 * 3", "<mrow><mn>1</mn><mo>+</mo><mrow><mn>2</mn><mo>&#x22C5;</mo><mn>3</mn></mrow></mrow>"},
+		{"(1 + 2) * 3", "<mrow><mrow><mo>(</mo><mrow><mn>1</mn><mo>+</mo><mn>2</mn></mrow><mo>)</mo></mrow><mo>&#x22C5;</mo><mn>3</mn></mrow>"},
+		{"a - (b - c)", "<mrow><mi>a</mi><mo>-</mo><mrow><mo>(</mo><mrow><mi>b</mi><mo>-</mo><mi>c</mi></mrow><mo>)</mo></mrow></mrow>"},
+		{"(a - b) - c", "<mrow><mrow><mi>a</mi><mo>-</mo><mi>b</mi></mrow><mo>-</mo><mi>c</mi></mrow>"},
+		{"(2 ^ 3) ^ 2", "<msup><mrow><mo>(</mo><msup><mn>2</mn><mn>3</mn></msup><mo>)</mo></mrow><mn>2</mn></msup>"},
+		{"2 ^ (1 + 1)", "<msup><mn>2</mn><mrow><mn>1</mn><mo>+</mo><mn>1</mn></mrow></msup>"},
+		{"(1 + 2) / 4", "<mfrac><mrow><mn>1</mn><mo>+</mo><mn>2</mn></mrow><mn>4</mn></mfrac>"},
+		{"2 \\ 9", "<msqrt><mn>9</mn></msqrt>"},
+		{"a < b", "<mrow><mi>a</mi><mo>&lt;</mo><mi>b</mi></mrow>"},
+	}
+	for _, tt := range tests {
+		n, err := Parse(tt.in)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.in, err)
+		}
+		want := `<math xmlns="http://www.w3.org/1998/Math/MathML">` + tt.want + "</math>"
+		if got := MathML(n); got != want {
+			t.Errorf("MathML(%q) = %q, want %q", tt.in, got, want)
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package vector
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Session holds variables, history and settings of one user,
+// its exported methods are safe for concurrent use
+type Session struct {
+	mu     sync.Mutex
+	memory Memory
+	graph  depGraph
+	cache  Memory
+	// gen is funcGen cache was filled at, -1 until first evaluation
+	gen     int
+	history []string
+	display display
+	epsilon tolerance
+	limits  Limits
+	lang    *locale
+	// traceOut receives trace of each evaluation when set
+	traceOut io.Writer
+}
+
+// NewSession returns empty session with default settings
+func NewSession() *Session {
+	return &Session{
+		memory:  Memory{},
+		graph:   depGraph{},
+		cache:   Memory{},
+		gen:     -1,
+		display: defaultDisplay,
+		epsilon: defaultEpsilon,
+		limits:  DefaultLimits,
+		lang:    english,
+	}
+}
+
+// std is session of package level functions like Run and Execute
+var std = NewSession()
+
+// funcGen counts changes of functions, caches of older generation are stale.
+// funcMu guards it
+var funcGen int
+
+// reset drops variables
+func (sess *Session) reset() {
+	sess.memory, sess.graph, sess.cache = Memory{}, depGraph{}, Memory{}
+}
+
+// Reset drops variables and history and restores default settings
+func (sess *Session) Reset() {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	sess.reset()
+	sess.history = nil
+	sess.display, sess.epsilon, sess.lang = defaultDisplay, defaultEpsilon, english
+}
+
+// Parse parses txt with decimal separator of language of session
+func (sess *Session) Parse(txt string) (Node, error) {
+	sess.mu.Lock()
+	lang := sess.lang
+	sess.mu.Unlock()
+
+	lexer := NewLexer(txt)
+	lexer.comma = lang.commaDecimal()
+	// parser goes on over bad tokens, so errors of both come at once
+	tokens, lexErr := lexer.GenerateTokens()
+	ast, err := NewParser(tokens).Parse()
+	return ast, lang.localize(joinErrs(lexErr, err))
+}
+
+// Check parses txt and reports where first error occurred as byte offsets start and end
+func (sess *Session) Check(txt string) (start, end int, err error) {
+	_, err = sess.Parse(txt)
+	if err == nil {
+		return 0, 0, nil
+	}
+	e, ok := err.(Error)
+	if list, isList := err.(ErrorList); isList {
+		e, ok = list[0], true
+	}
+	if ok && e.Span.Known() {
+		return e.Span.Start, e.Span.End, err
+	}
+	return len(txt), len(txt), err
+}
+
+// Input is expression of script, Off is byte offset of Text in line Line counting from 0
+type Input struct {
+	Line int
+	Off  int
+	Text string
+}
+
+// Inputs returns lines of script src holding expression, blank lines and lines
+// starting with # are skipped. Scripts holding "$ input" lines are read as
+// transcripts like vec test does, then only those lines are inputs
+func Inputs(src string) []Input {
+	lines := strings.Split(src, "\n")
+	transcript := false
+	for _, l := range lines {
+		if strings.HasPrefix(strings.TrimSpace(l), "$ ") {
+			transcript = true
+			break
+		}
+	}
+
+	var res []Input
+	for i, l := range lines {
+		l = strings.TrimRight(l, "\r")
+		text := strings.TrimSpace(l)
+		if transcript {
+			if !strings.HasPrefix(text, "$ ") {
+				continue
+			}
+			text = strings.TrimSpace(text[2:])
+			// inline output like $ 1 + 1 >> 2
+			if j := strings.Index(text, " >>"); j >= 0 {
+				text = strings.TrimSpace(text[:j])
+			}
+		}
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		res = append(res, Input{Line: i, Off: strings.Index(l, text), Text: text})
+	}
+	return res
+}
+
+// ParseScript parses each input of src, see Inputs. Errors of all lines come at
+// once as ErrorList with spans in src, errors at end of line point there
+func (sess *Session) ParseScript(src string) ([]Node, error) {
+	var nodes []Node
+	var errs ErrorList
+	// starts holds offset of each line in src
+	starts := []int{0}
+	for i, c := range src {
+		if c == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	for _, in := range Inputs(src) {
+		start := starts[in.Line] + in.Off
+		ast, err := sess.Parse(in.Text)
+		nodes = append(nodes, ast)
+		list, ok := err.(ErrorList)
+		if e, single := err.(Error); single {
+			list, ok = ErrorList{e}, true
+		}
+		if !ok {
+			continue
+		}
+		for _, e := range list {
+			if !e.Span.Known() {
+				e.Span = Span{len(in.Text), len(in.Text)}
+			}
+			errs = append(errs, e.at(start+e.Span.Start, start+e.Span.End))
+		}
+	}
+	return nodes, errs.err()
+}
+
+// Run parses and executes txt
+func (sess *Session) Run(ctx context.Context, txt string) (Node, error) {
+	ast, err := sess.Parse(txt)
+	if err != nil {
+		return nil, err
+	}
+	return sess.Execute(ctx, ast)
+}
+
+// Execute executes syntax tree and keeps result as ans
+func (sess *Session) Execute(ctx context.Context, ast Node) (Node, error) {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	res, err := newState(ctx, sess).run(ast)
+	if err != nil {
+		return nil, sess.lang.localize(err)
+	}
+
+	switch res.(type) {
+	case VecNode, NumberNode, BoolNode, ListNode, LambdaNode:
+		sess.store(kwANS.name, res, nil)
+	}
+	// history starts over after reset
+	if cmd, ok := ast.(CmdNode); !ok || cmd.kw.name != kwRESET.name {
+		sess.history = append(sess.history, Format(ast))
+	}
+	if max := sess.limits.MaxHistory; max > 0 && len(sess.history) > max {
+		sess.history = sess.history[len(sess.history)-max:]
+	}
+	return res, nil
+}
+
+// Show returns n like the shell prints it, numbers use settings of session
+func (sess *Session) Show(n Node) string {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	return sess.display.show(n)
+}
+
+// Var describes variable or constant of session, failing values hold error
+type Var struct {
+	Name       string
+	Expression string
+	Value      string
+	Type       string
+	Const      bool
+}
+
+// Vars returns variables sorted by name with ans last, followed by constants
+func (sess *Session) Vars() []Var {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	return vars(newState(context.Background(), sess))
+}
+
+// vars describes variables of s.sess and constants
+func vars(s *state) []Var {
+	var res []Var
+	for _, name := range s.sess.varNames() {
+		v := Var{Name: name, Expression: Format(s.sess.memory[name])}
+		val, err := s.resolve(VarNode{ident: Token{ttype: tIDENT, val: name}})
+		if err != nil {
+			v.Value = s.sess.lang.localize(err).Error()
+		} else {
+			v.Value, v.Type = s.sess.display.show(val), typeName(val)
+		}
+		res = append(res, v)
+	}
+
+	var consts []string
+	for name := range constants {
+		consts = append(consts, name)
+	}
+	sort.Strings(consts)
+	for _, name := range consts {
+		c := constants[name]
+		res = append(res, Var{Name: name, Value: s.sess.display.show(c), Type: typeName(c), Const: true})
+	}
+	return res
+}
+
+// Export returns variables as assignments in input syntax
+func (sess *Session) Export() string {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	return sess.export()
+}
+
+// History returns executed input in input syntax
+func (sess *Session) History() []string {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	return append([]string(nil), sess.history...)
+}
+
+// SetFormat changes number output like the format keyword
+func (sess *Session) SetFormat(args ...string) error {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	return sess.setFormat(args...)
+}
+
+// SetTrace writes evaluation steps of following runs to w, nil turns tracing off
+func (sess *Session) SetTrace(w io.Writer) {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	sess.traceOut = w
+}
+
+// SetEpsilon changes default tolerance like the eps keyword
+func (sess *Session) SetEpsilon(args ...string) error {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	return sess.setEpsilon(args...)
+}
+
+// SetLimits sets limits of following evaluations
+func (sess *Session) SetLimits(l Limits) {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	sess.limits = l
+}
+
+func (sess *Session) String() string {
+	return fmt.Sprintf("session(%d vars)", len(sess.memory))
+}
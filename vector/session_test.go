@@ -0,0 +1,84 @@
+package vector
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestInputs(t *testing.T) {
+	tests := []struct {
+		src  string
+		want []Input
+	}{
+		{"a = 1\n  # note\n\n  b = 2\r\n", []Input{{0, 0, "a = 1"}, {3, 2, "b = 2"}}},
+		{"$ a = 1\n>> \n$  a + 1 >> 2\ntext\n", []Input{{0, 2, "a = 1"}, {2, 3, "a + 1"}}},
+	}
+	for _, tt := range tests {
+		if got := Inputs(tt.src); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Inputs(%q) = %v, want %v", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestParseScript(t *testing.T) {
+	src := "$ a = 1\n>> \n$ b = a + * 2\n$ (1\n"
+	nodes, err := NewSession().ParseScript(src)
+	if len(nodes) != 3 {
+		t.Errorf("ParseScript returned %d nodes, want 3", len(nodes))
+	}
+	list, ok := err.(ErrorList)
+	if !ok || len(list) != 2 {
+		t.Fatalf("ParseScript = %v, want 2 errors", err)
+	}
+	// spans are offsets in src, missing end points to end of its line
+	if got := src[list[0].Span.Start:list[0].Span.End]; got != "*" {
+		t.Errorf("first error at %q, want *", got)
+	}
+	if got, want := list[1].Span, (Span{len(src) - 1, len(src) - 1}); got != want {
+		t.Errorf("second error at %v, want %v", got, want)
+	}
+}
+
+func TestCommands(t *testing.T) {
+	tests := []struct {
+		src  string
+		want Command
+		kind string
+	}{
+		{"quit", Command{Kind: CmdExit}, "exit"},
+		{"exit", Command{Kind: CmdExit}, "exit"},
+		{"close", Command{Kind: CmdExit}, "exit"},
+		{"clear", Command{Kind: CmdClear}, "clear"},
+		{"cls", Command{Kind: CmdClear}, "clear"},
+		{"help", Command{Kind: CmdHelp, Text: helpText}, "help"},
+		{"export", Command{Kind: CmdOutput, Text: "a = 2"}, "output"},
+	}
+	for _, tt := range tests {
+		sess := NewSession()
+		if _, err := sess.Run(context.Background(), "a = 2"); err != nil {
+			t.Fatal(err)
+		}
+		res, err := sess.Run(context.Background(), tt.src)
+		if err != nil || res != tt.want {
+			t.Errorf("%s = %#v %v, want %#v", tt.src, res, err, tt.want)
+			continue
+		}
+		// frontends show Text, commands are no value kept as ans
+		if got := sess.Show(res); got != tt.want.Text {
+			t.Errorf("Show(%s) = %q, want %q", tt.src, got, tt.want.Text)
+		}
+		if got := tt.want.Kind.String(); got != tt.kind {
+			t.Errorf("kind of %s = %q, want %q", tt.src, got, tt.kind)
+		}
+		if _, err := sess.Run(context.Background(), "ans"); err == nil {
+			t.Errorf("%s was kept as ans", tt.src)
+		}
+	}
+
+	sess := NewSession()
+	sess.SetLang("de")
+	if res, err := sess.Run(context.Background(), "help"); err != nil || res != (Command{Kind: CmdHelp, Text: helpTextDE}) {
+		t.Errorf("help in German = %v %v", res, err)
+	}
+}
@@ -0,0 +1,105 @@
+package vector
+
+import "context"
+
+// Limits bounds evaluation and history of session, zero disables limit
+type Limits struct {
+	MaxDepth  int
+	MaxVecLen int
+	MaxSteps  int
+	// MaxHistory bounds entries of history, oldest are dropped
+	MaxHistory int
+}
+
+// DefaultLimits are limits used until SetLimits is called
+var DefaultLimits = Limits{MaxDepth: 1000, MaxVecLen: 1 << 20, MaxSteps: 1000000, MaxHistory: 1000}
+
+// SetLimits sets limits of following evaluations
+func SetLimits(l Limits) {
+	std.SetLimits(l)
+}
+
+// state carries bookkeeping of one evaluation in sess
+type state struct {
+	ctx    context.Context
+	sess   *Session
+	limits Limits
+	depth  int
+	steps  int
+	scopes []Memory
+	trace  *tracer
+	// foreign counts nodes being resolved which are not from current input,
+	// positions of their tokens belong to other input
+	foreign int
+	// host counts running registered functions, Funcs passed to them evaluate here
+	host int
+}
+
+func newState(ctx context.Context, sess *Session) *state {
+	// registered functions may change cached results
+	funcMu.RLock()
+	gen := funcGen
+	funcMu.RUnlock()
+	if sess.gen != gen {
+		sess.cache, sess.gen = Memory{}, gen
+	}
+	return &state{ctx: ctx, sess: sess, limits: sess.limits}
+}
+
+// resolve resolves n checking cancellation and limits
+func (s *state) resolve(n Node) (Node, error) {
+	s.steps++
+	if s.limits.MaxSteps > 0 && s.steps > s.limits.MaxSteps {
+		return nil, newErr(ErrSteps, s.limits.MaxSteps)
+	}
+	// checking ctx takes a lock, so only look every few steps
+	if s.steps%64 == 1 {
+		if err := s.ctx.Err(); err != nil {
+			return nil, canceled(err)
+		}
+	}
+
+	s.depth++
+	defer func() { s.depth-- }()
+	if s.limits.MaxDepth > 0 && s.depth > s.limits.MaxDepth {
+		return nil, newErr(ErrDepth, s.limits.MaxDepth)
+	}
+
+	var step int
+	if s.trace != nil {
+		step = s.trace.enter(s, n)
+	}
+	res, err := n.resolve(s)
+	if s.trace != nil {
+		s.trace.leave(step, res, err)
+	}
+	if err != nil {
+		return nil, s.place(err, n)
+	}
+	if vec, ok := res.(VecNode); ok && s.limits.MaxVecLen > 0 && len(vec.fields) > s.limits.MaxVecLen {
+		return nil, newErr(ErrLength, s.limits.MaxVecLen)
+	}
+	return res, nil
+}
+
+// place gives err without position the position of n in input
+func (s *state) place(err error, n Node) error {
+	e, ok := err.(Error)
+	if !ok || e.Span.Known() || s.foreign > 0 {
+		return err
+	}
+	var tok Token
+	switch n := n.(type) {
+	case OperationNode:
+		tok = n.op
+	case UnaryNode:
+		tok = n.op
+	case VarNode:
+		tok = n.ident
+	case FuncNode:
+		return e.at(n.pos, n.pos+len(n.fun))
+	default:
+		return err
+	}
+	return e.at(tok.pos, tok.pos+len(tok.val))
+}
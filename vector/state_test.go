@@ -0,0 +1,61 @@
+package vector
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLimits(t *testing.T) {
+	tests := []struct {
+		limits Limits
+		setup  string
+		src    string
+		want   Code
+	}{
+		{Limits{MaxDepth: 5}, "", "1+(1+(1+(1+(1+(1+1)))))", ErrDepth},
+		{Limits{MaxSteps: 10}, "", "sum({1; 2; 3; 4; 5; 6; 7; 8; 9; 10; 11; 12})", ErrSteps},
+		{Limits{MaxVecLen: 2}, "", "[1 2 3]", ErrLength},
+		{Limits{MaxDepth: 8}, "f = x -> x + 1", strings.Repeat("f(", 10) + "1" + strings.Repeat(")", 10), ErrDepth},
+		{Limits{MaxDepth: 8}, "f = x -> x + 1", "f(f(1))", 0},
+		{Limits{}, "", "1+(1+(1+(1+(1+(1+1)))))", 0},
+	}
+	for _, tt := range tests {
+		sess := NewSession()
+		sess.SetLimits(tt.limits)
+		if tt.setup != "" {
+			if _, err := sess.Run(context.Background(), tt.setup); err != nil {
+				t.Fatal(err)
+			}
+		}
+		_, err := sess.Run(context.Background(), tt.src)
+		if tt.want == 0 && err != nil || tt.want != 0 && !errors.Is(err, tt.want) {
+			t.Errorf("%+v: Run(%q) = %v, want %v", tt.limits, tt.src, err, tt.want)
+		}
+	}
+}
+
+func TestRunContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+	_, err := NewSession().Run(ctx, "1 + 2")
+	if !errors.Is(err, ErrCanceled) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Run = %v, want %v wrapping %v", err, ErrCanceled, context.DeadlineExceeded)
+	}
+}
+
+func TestHistoryLimit(t *testing.T) {
+	sess := NewSession()
+	sess.SetLimits(Limits{MaxHistory: 2})
+	for _, src := range []string{"a = 1", "b = 2", "a + b"} {
+		if _, err := sess.Run(context.Background(), src); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := strings.Join(sess.History(), "; "); got != "b = 2; a + b" {
+		t.Errorf("History() = %q, want %q", got, "b = 2; a + b")
+	}
+}
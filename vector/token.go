@@ -20,12 +20,28 @@ const (
 	tPOW
 	tROOT
 	tEQ
+	tDEFINE
 	tLPAREN
 	tRPAREN
 	tLVECPAR
 	tRVECPAR
 	tABSQ
 	tABS
+	tARROW
+	tLLIST
+	tRLIST
+	tLT
+	tGT
+	tLE
+	tGE
+	tEQEQ
+	tNE
+	tAND
+	tOR
+	tNOT
+	tAPPROX
+	// tBAD holds input lexer could not read, its error is reported already
+	tBAD
 )
 
 var sTypes = []string{
@@ -43,12 +59,27 @@ var sTypes = []string{
 	"POW",
 	"ROOT",
 	"EQ",
+	"DEFINE",
 	"LPAREN",
 	"RPAREN",
 	"LVECPAR",
 	"RVECPAR",
 	"ABSQ",
 	"ABS",
+	"ARROW",
+	"LLIST",
+	"RLIST",
+	"LT",
+	"GT",
+	"LE",
+	"GE",
+	"EQEQ",
+	"NE",
+	"AND",
+	"OR",
+	"NOT",
+	"APPROX",
+	"BAD",
 }
 
 // TokenType is Token typ
@@ -58,10 +89,11 @@ func (t TokenType) String() string {
 	return sTypes[t]
 }
 
-// Token is Token
+// Token is Token, pos is its offset in input
 type Token struct {
 	ttype TokenType
 	val   string
+	pos   int
 }
 
 func (t Token) String() string {
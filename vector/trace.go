@@ -0,0 +1,105 @@
+package vector
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// SetTrace writes evaluation steps of following runs to w, nil turns tracing off
+func SetTrace(w io.Writer) {
+	std.SetTrace(w)
+}
+
+// traceStep is one resolved node, from tells where variable was found
+type traceStep struct {
+	depth int
+	node  Node
+	res   Node
+	err   error
+	from  string
+}
+
+// tracer records steps of evaluation in order they start
+type tracer struct {
+	base  int
+	steps []traceStep
+}
+
+func newTracer(s *state) *tracer {
+	return &tracer{base: s.depth + 1}
+}
+
+// enter records n before it resolves and returns index of its step
+func (t *tracer) enter(s *state, n Node) int {
+	step := traceStep{depth: s.depth - t.base, node: n}
+	if v, ok := n.(VarNode); ok && v.val == nil {
+		step.from = "memory"
+		if _, ok := constants[v.ident.val]; ok {
+			step.from = "const"
+		}
+		for _, scope := range s.scopes {
+			if _, ok := scope[v.ident.val]; ok {
+				step.from = "param"
+			}
+		}
+	}
+	t.steps = append(t.steps, step)
+	return len(t.steps) - 1
+}
+
+func (t *tracer) leave(i int, res Node, err error) {
+	t.steps[i].res, t.steps[i].err = res, err
+}
+
+// show returns steps as indented tree with numbers of d, nodes which resolve to themselves are left out
+func (t *tracer) show(d display) string {
+	var b strings.Builder
+	for _, st := range t.steps {
+		expr := Format(st.node)
+		if st.err == nil && st.res != nil && Format(st.res) == expr {
+			continue
+		}
+		b.WriteString(strings.Repeat("  ", st.depth) + expr)
+		switch {
+		case st.err != nil:
+			b.WriteString("  ! " + st.err.Error())
+		case st.res != nil:
+			b.WriteString(" = " + d.show(st.res))
+		}
+		if st.from != "" {
+			b.WriteString("  (" + st.from + ")")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// run resolves top level node and writes trace if tracing of session is on
+func (s *state) run(n Node) (Node, error) {
+	if s.sess.traceOut != nil {
+		s.trace = newTracer(s)
+	}
+	res, err := s.resolve(n)
+	if s.trace != nil {
+		fmt.Fprint(s.sess.traceOut, s.trace.show(s.sess.display))
+	}
+	return res, err
+}
+
+// TraceNode evaluates expr and shows its steps instead of result
+type TraceNode struct {
+	expr Node
+}
+
+func (n TraceNode) resolve(s *state) (Node, error) {
+	outer := s.trace
+	s.trace = newTracer(s)
+	defer func() { s.trace = outer }()
+	s.resolve(n.expr)
+	return output(strings.TrimRight(s.trace.show(s.sess.display), "\n")), nil
+}
+
+func (n TraceNode) String() string {
+	return fmt.Sprintf("TRACE(%s)", n.expr)
+}
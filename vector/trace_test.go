@@ -0,0 +1,51 @@
+package vector
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestTracePerSession(t *testing.T) {
+	var buf bytes.Buffer
+	traced, quiet := NewSession(), NewSession()
+	traced.SetTrace(&buf)
+	traced.SetFormat("digits", "3")
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			quiet.Run(context.Background(), "1 / 3 + 1")
+		}()
+	}
+	if _, err := traced.Run(context.Background(), "1 / 3 + 1"); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+	want := "1 / 3 + 1 = 1.33\n  1 / 3 = 0.333\n"
+	if got := buf.String(); got != want {
+		t.Errorf("trace = %q, want %q", got, want)
+	}
+}
+
+func TestTraceKeyword(t *testing.T) {
+	sess := NewSession()
+	sess.SetFormat("digits", "2")
+	for _, src := range []string{"a = 2 / 3", "f = x -> x * a"} {
+		if _, err := sess.Run(context.Background(), src); err != nil {
+			t.Fatal(err)
+		}
+	}
+	res, err := sess.Run(context.Background(), "trace f(3) + pi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"f(3) + pi = 5.1", "a = 0.67  (memory)", "pi = 3.1  (const)", "x = 3  (param)"} {
+		if !strings.Contains(res.String(), want) {
+			t.Errorf("trace lacks %q:\n%s", want, res)
+		}
+	}
+}
@@ -1,8 +1,8 @@
 package vector
 
 import (
-	"fmt"
-	"runtime"
+	"context"
+	"math"
 )
 
 // VERSION is version
@@ -11,29 +11,53 @@ const VERSION = "2.10.20"
 // Memory stores Ident, Node values
 type Memory map[string]Node
 
-var memory = Memory{}
+// constants are read only variables
+var constants = Memory{
+	"pi": NumberNode(math.Pi),
+	"e":  NumberNode(math.E),
+}
+
+// Parse parses txt into syntax tree
+func Parse(txt string) (Node, error) {
+	return std.Parse(txt)
+}
+
+// Check parses txt and reports where error occurred as byte offsets start and end
+func Check(txt string) (start, end int, err error) {
+	return std.Check(txt)
+}
+
+// ParseScript parses lines of src, see Session.ParseScript
+func ParseScript(src string) ([]Node, error) {
+	return std.ParseScript(src)
+}
+
+// Assigned returns variable which txt assigns and its offset, ok is false for other input
+func Assigned(txt string) (name string, pos int, ok bool) {
+	ast, err := Parse(txt)
+	if v, isVar := ast.(VarNode); err == nil && isVar && v.val != nil {
+		return v.ident.val, v.ident.pos, true
+	}
+	return "", 0, false
+}
 
 // Run runs txt
 func Run(txt string) (Node, error) {
-	lexer := NewLexer(txt)
-	tokens, err := lexer.GenerateTokens()
-	if err != nil {
-		return nil, err
-	}
+	return RunContext(context.Background(), txt)
+}
 
-	parser := NewParser(tokens)
-	ast, err := parser.Parse()
+// RunContext runs txt, evaluation stops when ctx is done
+func RunContext(ctx context.Context, txt string) (Node, error) {
+	ast, err := Parse(txt)
 	if err != nil {
-		switch err.(type) {
-		case ExitErr:
-			return nil, err
-		}
 		return nil, err
 	}
 
-	res, err := ast.resolve()
+	std.mu.Lock()
+	defer std.mu.Unlock()
+	res, err := newState(ctx, std).run(ast)
 	if err != nil {
-		return nil, err
+		return nil, std.lang.localize(err)
 	}
 
 	return res, nil
@@ -41,23 +65,10 @@ func Run(txt string) (Node, error) {
 
 // Execute executes syntax tree
 func Execute(ast Node) (Node, error) {
-	res, err := ast.resolve()
-	if err != nil {
-		return nil, err
-	}
-
-	switch res.(type) {
-	case VecNode, NumberNode:
-		memory["ans"] = res
-	}
-
-	switch runtime.GOOS {
-	case "js":
-		fmt.Println(memory)
-		// case "windows", "linux":
-		// 	fmt.Println(memory)
-	}
-	fmt.Println(memory)
+	return std.Execute(context.Background(), ast)
+}
 
-	return res, nil
+// Show returns n in number format set by format keyword
+func Show(n Node) string {
+	return std.Show(n)
 }
@@ -0,0 +1,105 @@
+//go:build js && wasm
+// +build js,wasm
+
+// Command wasm exposes vector to JavaScript when compiled with GOOS=js GOARCH=wasm.
+//
+// It registers global functions:
+//
+//	evaluate(input) -> {kind: "result", result, type} | {kind: "output", output}
+//	                 | {kind: "clear"} | {kind: "exit"}
+//	                 | {kind: "error", error: {type, message, code, hint, span: {start, end}}}
+//	getVars()       -> [{name, expression, value, type, const}]
+//	reset()         -> drops variables, history and settings
+package main
+
+import (
+	"context"
+	"errors"
+	"syscall/js"
+	"time"
+
+	"github.com/stetide/vector/vector"
+)
+
+// timeout bounds one evaluation, the browser tab freezes while it runs
+const timeout = 2 * time.Second
+
+var sess = vector.NewSession()
+
+func evaluate(this js.Value, args []js.Value) interface{} {
+	if len(args) != 1 || args[0].Type() != js.TypeString {
+		return failure(errors.New("evaluate expects input string"))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	res, err := sess.Run(ctx, args[0].String())
+	if err != nil {
+		return failure(err)
+	}
+	switch cmd := res.(type) {
+	case nil:
+		return map[string]interface{}{"kind": "result"}
+	case vector.Command:
+		switch cmd.Kind {
+		case vector.CmdOutput, vector.CmdHelp:
+			return map[string]interface{}{"kind": "output", "output": cmd.Text}
+		}
+		return map[string]interface{}{"kind": cmd.Kind.String()}
+	}
+	return map[string]interface{}{"kind": "result", "result": sess.Show(res), "type": vector.TypeName(res)}
+}
+
+// failure describes err, errors of vector carry code, hint and span
+func failure(err error) map[string]interface{} {
+	return map[string]interface{}{"kind": "error", "error": describe(err)}
+}
+
+// describe returns err as object, several syntax errors are all in errors
+func describe(err error) map[string]interface{} {
+	if list, ok := err.(vector.ErrorList); ok {
+		desc := describe(list[0])
+		var all []interface{}
+		for _, e := range list {
+			all = append(all, describe(e))
+		}
+		desc["errors"] = all
+		return desc
+	}
+	desc := map[string]interface{}{"type": "request", "message": err.Error()}
+	var verr vector.Error
+	if errors.As(err, &verr) {
+		desc["type"], desc["message"] = verr.Category().String(), verr.Msg
+		desc["code"], desc["hint"] = verr.Code.String(), verr.Hint
+		if verr.Span.Known() {
+			desc["span"] = map[string]interface{}{"start": verr.Span.Start, "end": verr.Span.End}
+		}
+	}
+	return desc
+}
+
+func getVars(this js.Value, args []js.Value) interface{} {
+	var res []interface{}
+	for _, v := range sess.Vars() {
+		res = append(res, map[string]interface{}{
+			"name":       v.Name,
+			"expression": v.Expression,
+			"value":      v.Value,
+			"type":       v.Type,
+			"const":      v.Const,
+		})
+	}
+	return res
+}
+
+func reset(this js.Value, args []js.Value) interface{} {
+	sess.Reset()
+	return nil
+}
+
+func main() {
+	js.Global().Set("evaluate", js.FuncOf(evaluate))
+	js.Global().Set("getVars", js.FuncOf(getVars))
+	js.Global().Set("reset", js.FuncOf(reset))
+	// functions stay callable while main blocks
+	select {}
+}